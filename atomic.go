@@ -0,0 +1,18 @@
+//go:build go1.19
+// +build go1.19
+
+package env
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+func init() {
+	atomicTypes[reflect.TypeOf(atomic.Bool{})] = reflect.TypeOf(false)
+	atomicTypes[reflect.TypeOf(atomic.Int32{})] = reflect.TypeOf(int32(0))
+	atomicTypes[reflect.TypeOf(atomic.Int64{})] = reflect.TypeOf(int64(0))
+	atomicTypes[reflect.TypeOf(atomic.Uint32{})] = reflect.TypeOf(uint32(0))
+	atomicTypes[reflect.TypeOf(atomic.Uint64{})] = reflect.TypeOf(uint64(0))
+	atomicTypes[reflect.TypeOf(atomic.Uintptr{})] = reflect.TypeOf(uintptr(0))
+}
@@ -0,0 +1,74 @@
+//go:build go1.19
+// +build go1.19
+
+package env_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+type AtomicStruct struct {
+	Requests atomic.Int64   `env:"ATOMIC_REQUESTS,min=1"`
+	Enabled  atomic.Bool    `env:"ATOMIC_ENABLED"`
+	Limit    *atomic.Uint32 `env:"ATOMIC_LIMIT,default=10"`
+	Address  atomic.Uintptr `env:"ATOMIC_ADDRESS,max=8"`
+}
+
+func TestUnmarshalAtomic(t *testing.T) {
+	m := map[string]string{
+		"ATOMIC_REQUESTS": "42",
+		"ATOMIC_ENABLED":  "true",
+		"ATOMIC_ADDRESS":  "5",
+	}
+
+	var atomicStruct AtomicStruct
+	err := env.UnmarshalMap(m, &atomicStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if atomicStruct.Requests.Load() != 42 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 42, atomicStruct.Requests.Load())
+	}
+
+	if !atomicStruct.Enabled.Load() {
+		t.Errorf("Expected field value to be '%t' but got '%t'", true, atomicStruct.Enabled.Load())
+	}
+
+	if atomicStruct.Limit == nil || atomicStruct.Limit.Load() != 10 {
+		t.Errorf("Expected field value to be '%d' but got '%v'", 10, atomicStruct.Limit)
+	}
+
+	if atomicStruct.Address.Load() != 5 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 5, atomicStruct.Address.Load())
+	}
+
+	data, err := env.Marshal(&atomicStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := "ATOMIC_REQUESTS=42\nATOMIC_ENABLED=true\nATOMIC_LIMIT=10\nATOMIC_ADDRESS=5\n"
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+
+	data, err = env.MarshalJSON(&atomicStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected = `{"ATOMIC_ADDRESS":5,"ATOMIC_ENABLED":true,"ATOMIC_LIMIT":10,"ATOMIC_REQUESTS":42}`
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+
+	m = map[string]string{"ATOMIC_ENABLED": "maybe"}
+	err = env.UnmarshalMap(m, &atomicStruct)
+	if err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
@@ -0,0 +1,126 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCronSchedule returned when the value of a CronSchedule field is
+// not a cron expression of 5 or 6 valid fields.
+var ErrInvalidCronSchedule = errors.New("cron schedule must have 5 or 6 valid fields")
+
+// CronSchedule is a cron expression such as "*/5 * * * *", with the fields
+// minute, hour, day of month, month and day of week, and an optional
+// leading seconds field. It is only validated syntactically.
+type CronSchedule struct {
+	// Fields are the fields of the expression, seconds first if there
+	// are six.
+	Fields []string
+}
+
+func init() {
+	RegisterParser(reflect.TypeOf(CronSchedule{}), func(value string) (interface{}, error) {
+		return ParseCronSchedule(value)
+	})
+}
+
+// cronField is the range of the values of a field of a cron expression,
+// the names accepted for them, if any, and whether it is a day field
+// accepting "?".
+type cronField struct {
+	min, max int
+	names    []string
+	day      bool
+}
+
+var (
+	cronSeconds = cronField{min: 0, max: 59}
+	cronFields  = []cronField{
+		{min: 0, max: 59},
+		{min: 0, max: 23},
+		{min: 1, max: 31, day: true},
+		{min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+		{min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}, day: true},
+	}
+)
+
+// ParseCronSchedule parses a cron expression of 5 or 6 fields separated by
+// whitespace. A field is a list of values, ranges such as 1-5 or "*",
+// each with an optional step such as */5. Months and days of the week may
+// be names such as JAN or MON, and "?" stands for "*" in the day fields.
+func ParseCronSchedule(value string) (CronSchedule, error) {
+	fields := strings.Fields(value)
+
+	specs := cronFields
+	switch len(fields) {
+	case 5:
+	case 6:
+		specs = append([]cronField{cronSeconds}, cronFields...)
+	default:
+		return CronSchedule{}, fmt.Errorf("%q: %w", value, ErrInvalidCronSchedule)
+	}
+
+	for i, field := range fields {
+		if !specs[i].valid(field) {
+			return CronSchedule{}, fmt.Errorf("%q: %w", value, ErrInvalidCronSchedule)
+		}
+	}
+	return CronSchedule{Fields: fields}, nil
+}
+
+// valid reports whether field is a valid list of values of c.
+func (c cronField) valid(field string) bool {
+	if c.day && field == "?" {
+		return true
+	}
+
+	for _, item := range strings.Split(field, ",") {
+		if i := strings.Index(item, "/"); i >= 0 {
+			step, err := strconv.Atoi(item[i+1:])
+			if err != nil || step <= 0 {
+				return false
+			}
+			item = item[:i]
+		}
+		if item == "*" {
+			continue
+		}
+
+		bounds := strings.SplitN(item, "-", 2)
+		low, ok := c.value(bounds[0])
+		if !ok {
+			return false
+		}
+		if len(bounds) == 2 {
+			high, ok := c.value(bounds[1])
+			if !ok || high < low {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// value returns the number of the value or name s of c, and reports
+// whether it is valid.
+func (c cronField) value(s string) (int, bool) {
+	for i, name := range c.names {
+		if strings.EqualFold(s, name) {
+			return c.min + i, true
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < c.min || n > c.max {
+		return 0, false
+	}
+	return n, true
+}
+
+// String returns the fields of s separated by spaces.
+func (s CronSchedule) String() string {
+	return strings.Join(s.Fields, " ")
+}
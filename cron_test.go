@@ -0,0 +1,51 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+func TestUnmarshalCronSchedule(t *testing.T) {
+	m := map[string]string{
+		"SCHEDULE":        "*/5 * * * *",
+		"BACKUP_SCHEDULE": "30 0 2 * * MON-FRI",
+		"REPORT_SCHEDULE": "0 9 1,15 JAN-jun ?",
+	}
+
+	var cronStruct struct {
+		Schedule env.CronSchedule  `env:"SCHEDULE"`
+		Backup   *env.CronSchedule `env:"BACKUP_SCHEDULE"`
+		Report   env.CronSchedule  `env:"REPORT_SCHEDULE"`
+	}
+	err := env.UnmarshalMap(m, &cronStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if cronStruct.Schedule.String() != "*/5 * * * *" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "*/5 * * * *", cronStruct.Schedule)
+	}
+
+	if cronStruct.Backup == nil || len(cronStruct.Backup.Fields) != 6 {
+		t.Errorf("Expected field value to have '%d' fields but got '%v'", 6, cronStruct.Backup)
+	}
+
+	for _, value := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "* * * * * * *", "5-1 * * * *", "* ? * * *", "* * * FOO *"} {
+		var invalidStruct struct {
+			Schedule env.CronSchedule `env:"SCHEDULE"`
+		}
+		err = env.UnmarshalMap(map[string]string{"SCHEDULE": value}, &invalidStruct)
+		if !errors.Is(err, env.ErrInvalidCronSchedule) {
+			t.Errorf("Expected error 'ErrInvalidCronSchedule' for '%s' but got '%v'", value, err)
+		}
+	}
+
+	err = env.UnmarshalMap(map[string]string{"SCHEDULE": "* * *"}, &cronStruct)
+
+	expected := `SCHEDULE: "* * *": cron schedule must have 5 or 6 valid fields`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%v'", expected, err)
+	}
+}
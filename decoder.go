@@ -0,0 +1,598 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Decoder unmarshals environment variables according to its options.
+type Decoder struct {
+	keyTransform      func(key string) string
+	fieldNameFallback bool
+	onMissing         func(key string) (string, bool)
+	blankAsEmpty      bool
+	source            Source
+	errorFormatter    func(fieldPath, key string, err error) string
+	skipUnsupported   bool
+	onSkip            func(fieldPath string, err error)
+
+	errorOnDuplicateKeys bool
+	fileVariables        bool
+	dashToUnderscore     bool
+	requireTag           bool
+	autoKeys             bool
+	caseInsensitiveKeys  bool
+	envPrefix            string
+	mapEntrySep          string
+	mapKeySep            string
+	bools                map[string]bool
+	timing               func(fieldPath string, d time.Duration)
+	tagOptionSep         string
+	valuePolicy          ValuePolicy
+	strictTypes          bool
+	defaults             interface{}
+}
+
+// Option configures a Decoder.
+type Option func(*Decoder)
+
+// NewDecoder returns a Decoder configured with opts.
+func NewDecoder(opts ...Option) *Decoder {
+	d := &Decoder{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Snapshot returns a Decoder configured with opts that reads the variables
+// of os.Environ at the time of the call, so that several structures are
+// unmarshaled from the same variables even if the environment changes. It
+// replaces the Source set by WithSource.
+func Snapshot(opts ...Option) *Decoder {
+	d := NewDecoder(opts...)
+	d.source = Map(environToEnvSet(os.Environ()).values)
+	return d
+}
+
+// WithKeyTransform sets a function applied to every key of a tag before it
+// is looked up.
+func WithKeyTransform(fn func(key string) string) Option {
+	return func(d *Decoder) {
+		d.keyTransform = fn
+	}
+}
+
+// WithEnvPrefix prepends prefix to every key, before the prefixes of
+// nested structures, so `env:"PORT"` looks up APP_PORT with the prefix
+// "APP_". The prefix applies to the variables of WithFileVariables too,
+// such as APP_PASSWORD_FILE.
+func WithEnvPrefix(prefix string) Option {
+	return func(d *Decoder) {
+		d.envPrefix = prefix
+	}
+}
+
+// WithMapEntrySeparator sets the separator of the entries of map fields
+// whose tag has no sep option, instead of ",".
+func WithMapEntrySeparator(sep string) Option {
+	return func(d *Decoder) {
+		d.mapEntrySep = sep
+	}
+}
+
+// WithMapKeySeparator sets the separator of the keys and values of map
+// fields whose tag has no kvsep option, instead of "=".
+func WithMapKeySeparator(sep string) Option {
+	return func(d *Decoder) {
+		d.mapKeySep = sep
+	}
+}
+
+// looseBools are the values accepted by WithLooseBools.
+var looseBools = map[string]bool{
+	"yes": true,
+	"y":   true,
+	"on":  true,
+	"no":  false,
+	"n":   false,
+	"off": false,
+}
+
+// WithLooseBools makes bool fields accept yes, y, on, no, n and off in any
+// case, besides the values of strconv.ParseBool.
+func WithLooseBools() Option {
+	return func(d *Decoder) {
+		if d.bools == nil {
+			d.bools = make(map[string]bool)
+		}
+		for value, b := range looseBools {
+			d.bools[value] = b
+		}
+	}
+}
+
+// WithBoolValues makes bool fields accept the values of trueValues as true
+// and those of falseValues as false, in any case, besides the values of
+// strconv.ParseBool and of other options. Defaults are parsed with the same
+// values, so `env:"DEBUG,default=si"` can be used with si as a true value.
+func WithBoolValues(trueValues, falseValues []string) Option {
+	return func(d *Decoder) {
+		if d.bools == nil {
+			d.bools = make(map[string]bool)
+		}
+		for _, value := range trueValues {
+			d.bools[strings.ToLower(value)] = true
+		}
+		for _, value := range falseValues {
+			d.bools[strings.ToLower(value)] = false
+		}
+	}
+}
+
+// WithStrictTypes makes number fields reject values of another kind of
+// number: integer fields reject floats such as 1.0, and float fields accept
+// only decimal floats with a fraction or an exponent, such as 1.0 or 1e3,
+// rejecting integers, hexadecimal floats, infinities and NaN. Values of
+// float fields with the percent option are exempt. Unmarshal returns an
+// error wrapping ErrNumberKind for rejected values.
+func WithStrictTypes() Option {
+	return func(d *Decoder) {
+		d.strictTypes = true
+	}
+}
+
+// WithTagOptionSeparator sets the separator of the keys and options of
+// tags, instead of ",", so that `env:"URL;default=a,b,c"` can be used with
+// ";". Values such as the default still take the rest of the tag.
+func WithTagOptionSeparator(sep string) Option {
+	return func(d *Decoder) {
+		d.tagOptionSep = sep
+	}
+}
+
+// WithFieldNameFallback makes fields whose tag has no key, such as
+// `env:",default=0"`, look up the Go field name as the key.
+func WithFieldNameFallback() Option {
+	return func(d *Decoder) {
+		d.fieldNameFallback = true
+	}
+}
+
+// WithAutoKeys is like WithFieldNameFallback but looks up the Go field
+// name in upper snake case, so MaxConns looks up MAX_CONNS.
+func WithAutoKeys() Option {
+	return func(d *Decoder) {
+		d.autoKeys = true
+	}
+}
+
+// WithCaseInsensitiveKeys makes keys match variables regardless of case,
+// so MAX_CONNS is found as max_conns. Variables are stored in catch-all
+// maps with upper case keys. A variable whose key is already upper case
+// takes precedence.
+func WithCaseInsensitiveKeys() Option {
+	return func(d *Decoder) {
+		d.caseInsensitiveKeys = true
+	}
+}
+
+// WithOnMissing sets a function called with the primary key of a field
+// whose variables are missing and which has no default. If fn reports the
+// key as handled, the returned value is used as if the variable was set.
+func WithOnMissing(fn func(key string) (value string, handled bool)) Option {
+	return func(d *Decoder) {
+		d.onMissing = fn
+	}
+}
+
+// WithDefaults sets a baseline structure, or pointer to a structure, of
+// the type unmarshaled by the Decoder. Its fields are copied to the
+// structure before decoding, so that they are kept when the variables of
+// a field are missing and the tag has neither a default nor a default
+// function. A field is thus set from, in order of precedence, its
+// variables, the default of its tag, base, and the zero value. Required
+// fields are satisfied by a baseline value that is not zero. The pointers,
+// slices and maps of base are copied too, so decoding never changes base
+// and a Decoder can be reused. Base must not contain cycles of pointers.
+//
+// If base is not of the type unmarshaled, Unmarshal returns an error
+// wrapping ErrInvalidValue.
+func WithDefaults(base interface{}) Option {
+	return func(d *Decoder) {
+		d.defaults = base
+	}
+}
+
+// deepCopy returns a copy of v that shares no pointers, slices or maps
+// with v. Unexported fields of structures are copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return c
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(deepCopy(v.Elem()))
+		return p
+	case reflect.Slice:
+		if v.IsNil() {
+			return c
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return c
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+		}
+		return m
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Struct:
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+	c.Set(v)
+	return c
+}
+
+// WithBlankAsEmpty makes values consisting only of whitespace count as
+// missing, so that the default of the field, its default function or the
+// function set by WithOnMissing is used, and fields tagged as required
+// reject them. Without a default, the field is left unchanged, as if the
+// variable were missing, but fields tagged as notempty reject the value.
+func WithBlankAsEmpty() Option {
+	return func(d *Decoder) {
+		d.blankAsEmpty = true
+	}
+}
+
+// ValuePolicy describes how a Decoder prepares the values of variables
+// before parsing them. The steps apply in the order of the fields.
+type ValuePolicy struct {
+	// Trim removes leading and trailing whitespace.
+	Trim bool
+
+	// Unquote removes matching single or double quotes around the value.
+	Unquote bool
+
+	// Expand replaces references to variables such as $HOST and ${HOST}
+	// with their values, trimmed and unquoted like the value, or with an
+	// empty string if they are missing. References are not expanded in
+	// the values they are replaced with.
+	Expand bool
+}
+
+// WithValuePolicy makes the Decoder prepare the values of variables
+// according to p, so the same policy applies to every source. Defaults are
+// used as written.
+func WithValuePolicy(p ValuePolicy) Option {
+	return func(d *Decoder) {
+		d.valuePolicy = p
+	}
+}
+
+// applyPolicy returns value prepared according to the value policy of d,
+// expanding references to the variables of es.
+func (d *Decoder) applyPolicy(es *envSet, value string) string {
+	value = d.valuePolicy.clean(value)
+	if d.valuePolicy.Expand {
+		value = os.Expand(value, func(key string) string {
+			return d.valuePolicy.clean(es.values[key])
+		})
+	}
+	return value
+}
+
+// clean returns value trimmed and unquoted according to p.
+func (p ValuePolicy) clean(value string) string {
+	if p.Trim {
+		value = strings.TrimSpace(value)
+	}
+	if p.Unquote {
+		value = unquote(value)
+	}
+	return value
+}
+
+// WithSource makes the Decoder read the variables of src instead of
+// os.Environ.
+func WithSource(src Source) Option {
+	return func(d *Decoder) {
+		d.source = src
+	}
+}
+
+// WithErrorFormatter sets the function rendering the text of field errors
+// from the dotted Go path of the field, the key of the variable and the
+// underlying error. By default the text is the key followed by a colon and
+// the underlying error.
+func WithErrorFormatter(fn func(fieldPath, key string, err error) string) Option {
+	return func(d *Decoder) {
+		d.errorFormatter = fn
+	}
+}
+
+// WithSkipUnsupported makes fields of unsupported types keep their values
+// instead of failing with ErrUnsupportedType. If fn is not nil, it is
+// called with the dotted Go path of every skipped field and the error.
+func WithSkipUnsupported(fn func(fieldPath string, err error)) Option {
+	return func(d *Decoder) {
+		d.skipUnsupported = true
+		d.onSkip = fn
+	}
+}
+
+// WithErrorOnDuplicateKeys makes Unmarshal fail with an error wrapping
+// ErrDuplicateKey when a field looks up a key, directly or as part of a
+// concat option, already looked up by another field, instead of setting
+// both fields. The keys are checked before any field is set.
+func WithErrorOnDuplicateKeys() Option {
+	return func(d *Decoder) {
+		d.errorOnDuplicateKeys = true
+	}
+}
+
+// WithFileVariables makes a field whose variables are missing read its
+// value from the file named by the variable of one of its keys suffixed
+// with "_FILE", such as DB_PASSWORD_FILE for DB_PASSWORD, like the file
+// tag option.
+func WithFileVariables() Option {
+	return func(d *Decoder) {
+		d.fileVariables = true
+	}
+}
+
+// WithDashToUnderscore replaces the dashes of the keys of the variables
+// with underscores before they are looked up, so MY-APP-PORT is found as
+// MY_APP_PORT. A variable whose key already has underscores takes
+// precedence.
+func WithDashToUnderscore() Option {
+	return func(d *Decoder) {
+		d.dashToUnderscore = true
+	}
+}
+
+// WithRequireTag makes Unmarshal fail with an error wrapping
+// ErrUntaggedField, listing the fields, if exported fields have no tag
+// "env". Embedded fields, fields tagged `env:"-"` and nested structures
+// with tagged fields are not reported, the fields of the latter are
+// checked instead.
+func WithRequireTag() Option {
+	return func(d *Decoder) {
+		d.requireTag = true
+	}
+}
+
+// WithTiming sets a function called with the dotted Go path of every
+// field and the time taken to look up, parse and validate its value.
+func WithTiming(fn func(fieldPath string, d time.Duration)) Option {
+	return func(d *Decoder) {
+		d.timing = fn
+	}
+}
+
+// Unmarshal parses os.Environ, or the Source set by WithSource, and stores
+// the result at the value pointed to by v. It returns the same errors as
+// the package-level Unmarshal.
+func (d *Decoder) Unmarshal(v interface{}) error {
+	return d.unmarshal(d.envSet(), v)
+}
+
+// UnmarshalPrefixed is like Unmarshal but prepends prefix to every key,
+// after the prefix set by WithEnvPrefix. It returns the variables not used
+// by any field, so that other consumers can read them.
+func (d *Decoder) UnmarshalPrefixed(prefix string, v interface{}) (map[string]string, error) {
+	prefixed := *d
+	prefixed.envPrefix += prefix
+
+	es := d.envSet()
+	err := prefixed.unmarshal(es, v)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := make(map[string]string)
+	for _, key := range es.unused() {
+		remaining[key] = es.values[key]
+	}
+	return remaining, nil
+}
+
+// fieldError returns err wrapped in a FieldError rendered by the error
+// formatter of d.
+func (d *Decoder) fieldError(fieldPath, key string, err error) error {
+	return &FieldError{
+		Field:  fieldPath,
+		Key:    key,
+		Err:    err,
+		format: d.errorFormatter,
+	}
+}
+
+// untaggedFields returns the dotted paths of the exported fields of the
+// structure type t without a tag "env", as reported by WithRequireTag.
+func untaggedFields(t reflect.Type, path string) []string {
+	var untagged []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Anonymous {
+			continue
+		}
+		if _, ok := field.Tag.Lookup("env"); ok {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct && hasTaggedFields(field.Type) {
+			untagged = append(untagged, untaggedFields(field.Type, path+field.Name+".")...)
+			continue
+		}
+		untagged = append(untagged, path+field.Name)
+	}
+	return untagged
+}
+
+// envSet returns the variables read by d.
+func (d *Decoder) envSet() *envSet {
+	var es *envSet
+	if d.source != nil {
+		es = newEnvSet(d.source.Environ())
+	} else {
+		es = environToEnvSet(os.Environ())
+	}
+
+	if d.dashToUnderscore {
+		es.values = dashToUnderscore(es.values)
+	}
+	if d.caseInsensitiveKeys {
+		es.values = upperKeys(es.values)
+	}
+	return es
+}
+
+// upperKeys returns a copy of values with upper case keys. Keys already in
+// upper case take precedence.
+func upperKeys(values map[string]string) map[string]string {
+	upper := make(map[string]string, len(values))
+	for key, value := range values {
+		upperKey := strings.ToUpper(key)
+		if _, ok := values[upperKey]; ok && upperKey != key {
+			continue
+		}
+		upper[upperKey] = value
+	}
+	return upper
+}
+
+// dashToUnderscore returns a copy of values with the dashes of its keys
+// replaced by underscores. Keys without dashes take precedence.
+func dashToUnderscore(values map[string]string) map[string]string {
+	normalized := make(map[string]string, len(values))
+	for key, value := range values {
+		normalized[key] = value
+	}
+	for key, value := range values {
+		if !strings.Contains(key, "-") {
+			continue
+		}
+		delete(normalized, key)
+		underscored := strings.ReplaceAll(key, "-", "_")
+		if _, ok := values[underscored]; !ok {
+			normalized[underscored] = value
+		}
+	}
+	return normalized
+}
+
+// valueTag returns t with the options of d that apply to the parsing of
+// every value, such as the bool values.
+func (d *Decoder) valueTag(t tag) tag {
+	t.Bools = d.bools
+	t.StrictTypes = d.strictTypes
+	return t
+}
+
+// parseTag parses the tag of field, prepends prefix to its keys and
+// applies the key and separator options of d.
+func (d *Decoder) parseTag(field reflect.StructField, tagString, prefix string) tag {
+	t := d.valueTag(d.parseTagOptions(tagString))
+	if indirect(field.Type).Kind() == reflect.Map {
+		if t.Sep == "" {
+			t.Sep = d.mapEntrySep
+		}
+		if t.KVSep == "" {
+			t.KVSep = d.mapKeySep
+		}
+	}
+	if (d.fieldNameFallback || d.autoKeys) && t.key() == "" {
+		name := field.Name
+		if d.autoKeys {
+			name = upperSnakeCase(name)
+		}
+		if len(t.Keys) == 0 {
+			t.Keys = []string{name}
+		} else {
+			t.Keys[0] = name
+		}
+	}
+	return d.transformKeys(t.withPrefix(d.envPrefix + prefix))
+}
+
+// parseTagOptions parses tagString with the tag option separator of d.
+func (d *Decoder) parseTagOptions(tagString string) tag {
+	if d.tagOptionSep == "" {
+		return parseTag(tagString)
+	}
+	return parseTagOptions(tagString, d.tagOptionSep)
+}
+
+// isInline is like the function isInline with the tag option separator of
+// d.
+func (d *Decoder) isInline(field reflect.StructField) bool {
+	tagString, ok := field.Tag.Lookup("env")
+	return ok && d.parseTagOptions(tagString).Inline
+}
+
+// transformKeys returns t with the key transform applied to its keys,
+// followed by upper casing when keys are case insensitive.
+func (d *Decoder) transformKeys(t tag) tag {
+	if d.keyTransform != nil {
+		t.Keys = transform(t.Keys, d.keyTransform)
+		t.Concat = transform(t.Concat, d.keyTransform)
+	}
+	if d.caseInsensitiveKeys {
+		t.Keys = transform(t.Keys, strings.ToUpper)
+		t.Concat = transform(t.Concat, strings.ToUpper)
+	}
+	return t
+}
+
+// upperSnakeCase returns the Go identifier name in upper snake case, such
+// as MAX_CONNS for MaxConns and HTTP_SERVER for HTTPServer.
+func upperSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func transform(keys []string, fn func(string) string) []string {
+	if keys == nil {
+		return nil
+	}
+
+	transformed := make([]string, len(keys))
+	for i, key := range keys {
+		transformed[i] = fn(key)
+	}
+	return transformed
+}
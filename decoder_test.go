@@ -0,0 +1,1016 @@
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+type KeyTransformStruct struct {
+	Port string `env:"port"`
+}
+
+func TestDecoderKeyTransform(t *testing.T) {
+	_ = os.Setenv("APP_PORT", "8080")
+
+	decoder := env.NewDecoder(env.WithKeyTransform(func(key string) string {
+		return "APP_" + strings.ToUpper(key)
+	}))
+
+	var keyTransformStruct KeyTransformStruct
+	err := decoder.Unmarshal(&keyTransformStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if keyTransformStruct.Port != "8080" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "8080", keyTransformStruct.Port)
+	}
+}
+
+type FieldNameFallbackStruct struct {
+	FallbackPort string `env:",default=0"`
+	FallbackHost string `env:"FALLBACK_EXPLICIT_HOST"`
+}
+
+func TestDecoderFieldNameFallback(t *testing.T) {
+	_ = os.Setenv("FallbackPort", "8080")
+	_ = os.Setenv("FallbackHost", "ignored")
+	_ = os.Setenv("FALLBACK_EXPLICIT_HOST", "localhost")
+
+	var fieldNameFallbackStruct FieldNameFallbackStruct
+	err := env.NewDecoder(env.WithFieldNameFallback()).Unmarshal(&fieldNameFallbackStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if fieldNameFallbackStruct.FallbackPort != "8080" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "8080", fieldNameFallbackStruct.FallbackPort)
+	}
+
+	if fieldNameFallbackStruct.FallbackHost != "localhost" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "localhost", fieldNameFallbackStruct.FallbackHost)
+	}
+}
+
+type OnMissingStruct struct {
+	Password string `env:"ON_MISSING_PASSWORD,required"`
+	User     string `env:"ON_MISSING_USER,default=admin"`
+	Name     string `env:"ON_MISSING_NAME"`
+}
+
+func TestDecoderOnMissing(t *testing.T) {
+	var keys []string
+	decoder := env.NewDecoder(env.WithOnMissing(func(key string) (string, bool) {
+		keys = append(keys, key)
+		if key == "ON_MISSING_PASSWORD" {
+			return "from-vault", true
+		}
+		return "", false
+	}))
+
+	var onMissingStruct OnMissingStruct
+	err := decoder.Unmarshal(&onMissingStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if onMissingStruct.Password != "from-vault" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "from-vault", onMissingStruct.Password)
+	}
+
+	if onMissingStruct.User != "admin" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "admin", onMissingStruct.User)
+	}
+
+	expected := []string{"ON_MISSING_PASSWORD", "ON_MISSING_NAME"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected callback keys to be '%v' but got '%v'", expected, keys)
+	}
+}
+
+type BlankAsEmptyStruct struct {
+	Name string `env:"BLANK_NAME,notempty"`
+}
+
+func TestDecoderBlankAsEmpty(t *testing.T) {
+	_ = os.Setenv("BLANK_NAME", "   ")
+
+	var blankAsEmptyStruct BlankAsEmptyStruct
+	err := env.NewDecoder(env.WithBlankAsEmpty()).Unmarshal(&blankAsEmptyStruct)
+	if !errors.Is(err, env.ErrEmptyValue) {
+		t.Errorf("Expected error 'ErrEmptyValue' but got '%v'", err)
+	}
+
+	err = env.Unmarshal(&blankAsEmptyStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if blankAsEmptyStruct.Name != "   " {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "   ", blankAsEmptyStruct.Name)
+	}
+}
+
+func TestDecoderBlankAsEmptyMissing(t *testing.T) {
+	m := env.Map{
+		"BLANK_REQUIRED": "  ",
+		"BLANK_DEFAULT":  "\t",
+		"BLANK_HANDLED":  " ",
+		"BLANK_PLAIN":    "  ",
+		"BLANK_PORT":     "  ",
+	}
+
+	var requiredStruct struct {
+		Name string `env:"BLANK_REQUIRED,required"`
+	}
+	err := env.NewDecoder(env.WithSource(m), env.WithBlankAsEmpty()).Unmarshal(&requiredStruct)
+	if !errors.Is(err, env.ErrMissingRequired) {
+		t.Errorf("Expected error 'ErrMissingRequired' but got '%v'", err)
+	}
+
+	err = env.NewDecoder(env.WithSource(m)).Unmarshal(&requiredStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	var blankStruct struct {
+		Default string `env:"BLANK_DEFAULT,default=fallback"`
+		Handled string `env:"BLANK_HANDLED"`
+		Plain   string `env:"BLANK_PLAIN"`
+		Port    int    `env:"BLANK_PORT"`
+	}
+	blankStruct.Port = 8080
+	decoder := env.NewDecoder(env.WithSource(m), env.WithBlankAsEmpty(), env.WithOnMissing(func(key string) (string, bool) {
+		return "handled", key == "BLANK_HANDLED"
+	}))
+	err = decoder.Unmarshal(&blankStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if blankStruct.Default != "fallback" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "fallback", blankStruct.Default)
+	}
+
+	if blankStruct.Handled != "handled" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "handled", blankStruct.Handled)
+	}
+
+	if blankStruct.Plain != "" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "", blankStruct.Plain)
+	}
+
+	if blankStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 8080, blankStruct.Port)
+	}
+}
+
+type ErrorFormatterStruct struct {
+	Server struct {
+		Port int `env:"FORMATTER_PORT,required"`
+	}
+}
+
+func TestDecoderErrorFormatter(t *testing.T) {
+	decoder := env.NewDecoder(
+		env.WithSource(env.Map{}),
+		env.WithErrorFormatter(func(fieldPath, key string, err error) string {
+			return fmt.Sprintf(`{"field":%q,"key":%q,"error":%q}`, fieldPath, key, err)
+		}),
+	)
+
+	var errorFormatterStruct ErrorFormatterStruct
+	err := decoder.Unmarshal(&errorFormatterStruct)
+	if !errors.Is(err, env.ErrMissingRequired) {
+		t.Errorf("Expected error 'ErrMissingRequired' but got '%v'", err)
+	}
+
+	expected := `{"field":"Server.Port","key":"FORMATTER_PORT","error":"required environment variable is missing"}`
+	if err != nil && err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, err)
+	}
+
+	err = env.UnmarshalMap(map[string]string{}, &errorFormatterStruct)
+	expected = "FORMATTER_PORT: required environment variable is missing"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%v'", expected, err)
+	}
+
+	var fieldError *env.FieldError
+	if !errors.As(err, &fieldError) {
+		t.Errorf("Expected error to be a FieldError but got '%v'", err)
+	} else if fieldError.Field != "Server.Port" || fieldError.Key != "FORMATTER_PORT" {
+		t.Errorf("Expected field '%s' and key '%s' but got '%s' and '%s'", "Server.Port", "FORMATTER_PORT", fieldError.Field, fieldError.Key)
+	}
+}
+
+func TestDecoderSkipUnsupported(t *testing.T) {
+	m := env.Map{
+		"SKIP_NAME":      "service",
+		"SKIP_TIMESTAMP": "2016-07-15T12:00:00Z",
+	}
+
+	var skipStruct struct {
+		Name      string    `env:"SKIP_NAME"`
+		Timestamp time.Time `env:"SKIP_TIMESTAMP"`
+	}
+	err := env.NewDecoder(env.WithSource(m)).Unmarshal(&skipStruct)
+	if err != env.ErrUnsupportedType {
+		t.Errorf("Expected error 'ErrUnsupportedType' but got '%v'", err)
+	}
+
+	var skipped []string
+	decoder := env.NewDecoder(
+		env.WithSource(m),
+		env.WithSkipUnsupported(func(fieldPath string, err error) {
+			skipped = append(skipped, fieldPath)
+		}),
+	)
+	err = decoder.Unmarshal(&skipStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if skipStruct.Name != "service" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "service", skipStruct.Name)
+	}
+
+	if !skipStruct.Timestamp.IsZero() {
+		t.Errorf("Expected field value to be zero but got '%s'", skipStruct.Timestamp)
+	}
+
+	expected := []string{"Timestamp"}
+	if !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("Expected skipped fields to be '%q' but got '%q'", expected, skipped)
+	}
+}
+
+func TestDecoderErrorOnDuplicateKeys(t *testing.T) {
+	m := env.Map{"DUPLICATE_HOST": "localhost"}
+
+	var duplicateStruct struct {
+		Host  string `env:"DUPLICATE_HOST"`
+		Alias string `env:"DUPLICATE_ALIAS,DUPLICATE_HOST"`
+	}
+	err := env.NewDecoder(env.WithSource(m)).Unmarshal(&duplicateStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if duplicateStruct.Host != "localhost" || duplicateStruct.Alias != "localhost" {
+		t.Errorf("Expected field values to be '%s' but got '%s' and '%s'", "localhost", duplicateStruct.Host, duplicateStruct.Alias)
+	}
+
+	decoder := env.NewDecoder(env.WithSource(m), env.WithErrorOnDuplicateKeys())
+	err = decoder.Unmarshal(&duplicateStruct)
+	if !errors.Is(err, env.ErrDuplicateKey) {
+		t.Errorf("Expected error 'ErrDuplicateKey' but got '%v'", err)
+	}
+
+	expected := "DUPLICATE_HOST: key is used by another field: Host"
+	if err != nil && err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, err)
+	}
+}
+
+func TestDecoderErrorOnDuplicateConcatKeys(t *testing.T) {
+	m := env.Map{"DUPLICATE_CONCAT_A": "a", "DUPLICATE_CONCAT_B": "b"}
+
+	var duplicateStruct struct {
+		A string `env:"DUPLICATE_CONCAT_A"`
+		T string `env:"DUPLICATE_CONCAT_T,concat=DUPLICATE_CONCAT_A,DUPLICATE_CONCAT_B"`
+	}
+	decoder := env.NewDecoder(env.WithSource(m), env.WithErrorOnDuplicateKeys())
+	err := decoder.Unmarshal(&duplicateStruct)
+	if !errors.Is(err, env.ErrDuplicateKey) {
+		t.Errorf("Expected error 'ErrDuplicateKey' but got '%v'", err)
+	}
+
+	if duplicateStruct.A != "" || duplicateStruct.T != "" {
+		t.Errorf("Expected field values to be empty but got '%s' and '%s'", duplicateStruct.A, duplicateStruct.T)
+	}
+}
+
+func TestDecoderFileVariables(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password")
+	err := os.WriteFile(filename, []byte("s3cr3t\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type fileVariablesStruct struct {
+		Password  string `env:"FILE_VARIABLES_PASSWORD"`
+		Preserved string `env:"FILE_VARIABLES_PRESERVED,keepnewline"`
+	}
+
+	m := env.Map{
+		"FILE_VARIABLES_PASSWORD_FILE":  filename,
+		"FILE_VARIABLES_PRESERVED_FILE": filename,
+	}
+
+	var ignoredStruct fileVariablesStruct
+	err = env.NewDecoder(env.WithSource(m)).Unmarshal(&ignoredStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if ignoredStruct.Password != "" {
+		t.Errorf("Expected field value to be empty but got '%s'", ignoredStruct.Password)
+	}
+
+	var fileStruct fileVariablesStruct
+	err = env.NewDecoder(env.WithSource(m), env.WithFileVariables()).Unmarshal(&fileStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if fileStruct.Password != "s3cr3t" {
+		t.Errorf("Expected field value to be '%q' but got '%q'", "s3cr3t", fileStruct.Password)
+	}
+
+	if fileStruct.Preserved != "s3cr3t\n" {
+		t.Errorf("Expected field value to be '%q' but got '%q'", "s3cr3t\n", fileStruct.Preserved)
+	}
+
+	m["FILE_VARIABLES_PASSWORD"] = "direct"
+	err = env.NewDecoder(env.WithSource(m), env.WithFileVariables()).Unmarshal(&fileStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if fileStruct.Password != "direct" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "direct", fileStruct.Password)
+	}
+}
+
+func TestDecoderDashToUnderscore(t *testing.T) {
+	m := env.Map{
+		"MY-APP-PORT": "8080",
+		"MY-APP-HOST": "dashed",
+		"MY_APP_HOST": "underscored",
+	}
+
+	var dashStruct struct {
+		Port int    `env:"MY_APP_PORT"`
+		Host string `env:"MY_APP_HOST"`
+	}
+	err := env.NewDecoder(env.WithSource(m), env.WithDashToUnderscore()).Unmarshal(&dashStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if dashStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 8080, dashStruct.Port)
+	}
+
+	if dashStruct.Host != "underscored" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "underscored", dashStruct.Host)
+	}
+}
+
+type RequireTagStruct struct {
+	EmbeddedBase
+
+	Name    string `env:"REQUIRE_TAG_NAME"`
+	Ignored string `env:"-"`
+	Extra   string
+
+	Server struct {
+		Port    int `env:"REQUIRE_TAG_PORT"`
+		Timeout time.Duration
+	}
+
+	internal string
+}
+
+func TestDecoderRequireTag(t *testing.T) {
+	m := env.Map{"REQUIRE_TAG_NAME": "service"}
+
+	var requireTagStruct RequireTagStruct
+	err := env.NewDecoder(env.WithSource(m)).Unmarshal(&requireTagStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	requireTagStruct = RequireTagStruct{}
+	err = env.NewDecoder(env.WithSource(m), env.WithRequireTag()).Unmarshal(&requireTagStruct)
+	if !errors.Is(err, env.ErrUntaggedField) {
+		t.Errorf("Expected error 'ErrUntaggedField' but got '%v'", err)
+	}
+
+	expected := "Extra, Server.Timeout: field has no env tag"
+	if err != nil && err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, err)
+	}
+
+	if requireTagStruct.Name != "" {
+		t.Errorf("Expected field value to be empty but got '%s'", requireTagStruct.Name)
+	}
+
+	_ = requireTagStruct.internal
+}
+
+func TestDecoderAutoKeysCaseInsensitive(t *testing.T) {
+	type autoKeysStruct struct {
+		MaxConns   int    `env:",default=0"`
+		HTTPServer string `env:",default=localhost"`
+		Name       string `env:"AUTO_KEYS_NAME"`
+	}
+
+	m := env.Map{
+		"max_conns":      "10",
+		"http_server":    "example.com",
+		"auto_keys_name": "service",
+	}
+
+	var autoStruct autoKeysStruct
+	err := env.NewDecoder(env.WithSource(m), env.WithAutoKeys()).Unmarshal(&autoStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if autoStruct.MaxConns != 0 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 0, autoStruct.MaxConns)
+	}
+
+	decoder := env.NewDecoder(env.WithSource(m), env.WithAutoKeys(), env.WithCaseInsensitiveKeys())
+	err = decoder.Unmarshal(&autoStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if autoStruct.MaxConns != 10 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 10, autoStruct.MaxConns)
+	}
+
+	if autoStruct.HTTPServer != "example.com" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "example.com", autoStruct.HTTPServer)
+	}
+
+	if autoStruct.Name != "service" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "service", autoStruct.Name)
+	}
+
+	m = env.Map{"MAX_CONNS": "20", "max_conns": "10"}
+	decoder = env.NewDecoder(env.WithSource(m), env.WithAutoKeys(), env.WithCaseInsensitiveKeys())
+	err = decoder.Unmarshal(&autoStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if autoStruct.MaxConns != 20 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 20, autoStruct.MaxConns)
+	}
+}
+
+func TestDecoderEnvPrefixFileVariables(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "password")
+	err := os.WriteFile(filename, []byte("s3cr3t\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := env.Map{
+		"APP_PORT":          "8080",
+		"APP_PASSWORD_FILE": filename,
+		"PASSWORD_FILE":     "unused",
+		"PORT":              "unused",
+	}
+
+	var prefixStruct struct {
+		Port     int    `env:"PORT"`
+		Password string `env:"PASSWORD,required"`
+	}
+	decoder := env.NewDecoder(env.WithSource(m), env.WithEnvPrefix("APP_"), env.WithFileVariables())
+	err = decoder.Unmarshal(&prefixStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if prefixStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 8080, prefixStruct.Port)
+	}
+
+	if prefixStruct.Password != "s3cr3t" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "s3cr3t", prefixStruct.Password)
+	}
+}
+
+func TestDecoderUnmarshalPrefixed(t *testing.T) {
+	m := env.Map{
+		"PLUGIN_NAME":    "cache",
+		"PLUGIN_SIZE":    "64",
+		"PLUGIN_UNKNOWN": "other",
+		"NAME":           "service",
+	}
+
+	var pluginStruct struct {
+		Name string `env:"NAME"`
+		Size int    `env:"SIZE"`
+	}
+	remaining, err := env.NewDecoder(env.WithSource(m)).UnmarshalPrefixed("PLUGIN_", &pluginStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if pluginStruct.Name != "cache" || pluginStruct.Size != 64 {
+		t.Errorf("Expected field values to be '%s' and '%d' but got '%s' and '%d'", "cache", 64, pluginStruct.Name, pluginStruct.Size)
+	}
+
+	expected := map[string]string{
+		"PLUGIN_UNKNOWN": "other",
+		"NAME":           "service",
+	}
+	if !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("Expected remaining variables to be '%v' but got '%v'", expected, remaining)
+	}
+}
+
+func TestDecoderMapSeparators(t *testing.T) {
+	m := env.Map{
+		"MAP_SEPARATORS_LABELS": "tier:1;team:core",
+		"MAP_SEPARATORS_LIMITS": "cpu:2;memory:4",
+		"MAP_SEPARATORS_TAGGED": "a=1&b=2",
+		"MAP_SEPARATORS_HOSTS":  "a;b",
+	}
+
+	var separatorsStruct struct {
+		Labels map[string]string `env:"MAP_SEPARATORS_LABELS"`
+		Limits map[string]int    `env:"MAP_SEPARATORS_LIMITS"`
+		Tagged map[string]int    `env:"MAP_SEPARATORS_TAGGED,sep=&,kvsep=="`
+		Hosts  []string          `env:"MAP_SEPARATORS_HOSTS"`
+	}
+	decoder := env.NewDecoder(
+		env.WithSource(m),
+		env.WithMapEntrySeparator(";"),
+		env.WithMapKeySeparator(":"),
+	)
+	err := decoder.Unmarshal(&separatorsStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedLabels := map[string]string{"tier": "1", "team": "core"}
+	if !reflect.DeepEqual(separatorsStruct.Labels, expectedLabels) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedLabels, separatorsStruct.Labels)
+	}
+
+	expectedLimits := map[string]int{"cpu": 2, "memory": 4}
+	if !reflect.DeepEqual(separatorsStruct.Limits, expectedLimits) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedLimits, separatorsStruct.Limits)
+	}
+
+	expectedTagged := map[string]int{"a": 1, "b": 2}
+	if !reflect.DeepEqual(separatorsStruct.Tagged, expectedTagged) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedTagged, separatorsStruct.Tagged)
+	}
+
+	expectedHosts := []string{"a;b"}
+	if !reflect.DeepEqual(separatorsStruct.Hosts, expectedHosts) {
+		t.Errorf("Expected field value to be '%q' but got '%q'", expectedHosts, separatorsStruct.Hosts)
+	}
+}
+
+func TestDecoderLooseBools(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"Yes", true},
+		{"ON", true},
+		{"y", true},
+		{"True", true},
+		{"Off", false},
+		{"NO", false},
+		{"0", false},
+	}
+
+	for _, test := range tests {
+		m := env.Map{"LOOSE_BOOL": test.value}
+
+		var looseStruct struct {
+			Flag bool `env:"LOOSE_BOOL"`
+		}
+		err := env.NewDecoder(env.WithSource(m), env.WithLooseBools()).Unmarshal(&looseStruct)
+		if err != nil {
+			t.Errorf("Expected no error for '%s' but got '%s'", test.value, err)
+		}
+
+		if looseStruct.Flag != test.expected {
+			t.Errorf("Expected field value for '%s' to be '%t' but got '%t'", test.value, test.expected, looseStruct.Flag)
+		}
+	}
+
+	m := env.Map{"LOOSE_BOOL": "Yes"}
+
+	var strictStruct struct {
+		Flag bool `env:"LOOSE_BOOL"`
+	}
+	err := env.NewDecoder(env.WithSource(m)).Unmarshal(&strictStruct)
+	if err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
+
+func TestDecoderTiming(t *testing.T) {
+	m := env.Map{"TIMING_NAME": "service", "TIMING_PORT": "8080"}
+
+	var timingStruct struct {
+		Name   string `env:"TIMING_NAME"`
+		Server struct {
+			Port int `env:"TIMING_PORT"`
+		}
+		Extra string
+	}
+
+	var fields []string
+	decoder := env.NewDecoder(
+		env.WithSource(m),
+		env.WithTiming(func(fieldPath string, d time.Duration) {
+			if d < 0 {
+				t.Errorf("Expected a positive duration for '%s' but got '%s'", fieldPath, d)
+			}
+			fields = append(fields, fieldPath)
+		}),
+	)
+	err := decoder.Unmarshal(&timingStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []string{"Name", "Server.Port"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected timed fields to be '%q' but got '%q'", expected, fields)
+	}
+}
+
+func TestDecoderTagOptionSeparator(t *testing.T) {
+	m := env.Map{"TAG_SEPARATOR_PORT": "8080"}
+
+	var separatorStruct struct {
+		URLs []string `env:"TAG_SEPARATOR_URLS;default=a,b,c"`
+		Port int      `env:"TAG_SEPARATOR_PORT;TAG_SEPARATOR_ALT_PORT;required;min=1"`
+	}
+	err := env.NewDecoder(env.WithSource(m), env.WithTagOptionSeparator(";")).Unmarshal(&separatorStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(separatorStruct.URLs, expected) {
+		t.Errorf("Expected field value to be '%q' but got '%q'", expected, separatorStruct.URLs)
+	}
+
+	if separatorStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 8080, separatorStruct.Port)
+	}
+
+	err = env.NewDecoder(env.WithSource(env.Map{}), env.WithTagOptionSeparator(";")).Unmarshal(&separatorStruct)
+	if !errors.Is(err, env.ErrMissingRequired) {
+		t.Errorf("Expected error 'ErrMissingRequired' but got '%v'", err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	_ = os.Setenv("SNAPSHOT_HOST", "localhost")
+	_ = os.Setenv("SNAPSHOT_PORT", "8080")
+
+	decoder := env.Snapshot()
+
+	_ = os.Setenv("SNAPSHOT_HOST", "changed")
+	_ = os.Unsetenv("SNAPSHOT_PORT")
+
+	var hostStruct struct {
+		Host string `env:"SNAPSHOT_HOST"`
+	}
+	err := decoder.Unmarshal(&hostStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	var portStruct struct {
+		Port int `env:"SNAPSHOT_PORT,required"`
+	}
+	err = decoder.Unmarshal(&portStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if hostStruct.Host != "localhost" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "localhost", hostStruct.Host)
+	}
+
+	if portStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 8080, portStruct.Port)
+	}
+}
+
+func TestDecoderBoolValues(t *testing.T) {
+	m := env.Map{"BOOL_PRESENT": "Nein"}
+
+	var boolStruct struct {
+		Present bool `env:"BOOL_PRESENT,defaultTrue"`
+		Default bool `env:"BOOL_DEFAULT,default=si"`
+	}
+	decoder := env.NewDecoder(env.WithSource(m), env.WithBoolValues([]string{"si", "ja"}, []string{"no", "nein"}))
+	err := decoder.Unmarshal(&boolStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if boolStruct.Present {
+		t.Errorf("Expected field value to be '%t' but got '%t'", false, boolStruct.Present)
+	}
+
+	if !boolStruct.Default {
+		t.Errorf("Expected field value to be '%t' but got '%t'", true, boolStruct.Default)
+	}
+
+	var strictStruct struct {
+		Default bool `env:"BOOL_DEFAULT,default=si"`
+	}
+	err = env.NewDecoder(env.WithSource(env.Map{})).Unmarshal(&strictStruct)
+	if err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
+
+func TestDecoderValuePolicy(t *testing.T) {
+	m := env.Map{
+		"POLICY_HOST": "  db.local ",
+		"POLICY_URL":  ` "postgres://${POLICY_USER}@$POLICY_HOST/app" `,
+		"POLICY_USER": "admin",
+	}
+
+	var policyStruct struct {
+		Host string `env:"POLICY_HOST"`
+		URL  string `env:"POLICY_URL"`
+		Name string `env:"POLICY_NAME,default= $POLICY_USER "`
+	}
+	decoder := env.NewDecoder(env.WithSource(m), env.WithValuePolicy(env.ValuePolicy{
+		Trim:    true,
+		Unquote: true,
+		Expand:  true,
+	}))
+	err := decoder.Unmarshal(&policyStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if policyStruct.Host != "db.local" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "db.local", policyStruct.Host)
+	}
+
+	expected := "postgres://admin@db.local/app"
+	if policyStruct.URL != expected {
+		t.Errorf("Expected field value to be '%s' but got '%s'", expected, policyStruct.URL)
+	}
+
+	if policyStruct.Name != " $POLICY_USER " {
+		t.Errorf("Expected field value to be '%s' but got '%s'", " $POLICY_USER ", policyStruct.Name)
+	}
+}
+
+func TestDecoderStrictTypes(t *testing.T) {
+	type strictStruct struct {
+		Workers int     `env:"STRICT_WORKERS"`
+		Port    uint16  `env:"STRICT_PORT"`
+		Ratio   float64 `env:"STRICT_RATIO"`
+		Scale   float32 `env:"STRICT_SCALE"`
+	}
+
+	testCases := []struct {
+		name     string
+		environ  env.Map
+		expected error
+	}{
+		{"exact values", env.Map{"STRICT_WORKERS": "4", "STRICT_PORT": "80", "STRICT_RATIO": "0.5", "STRICT_SCALE": "1e3"}, nil},
+		{"float into int", env.Map{"STRICT_WORKERS": "1.0"}, env.ErrNumberKind},
+		{"float into uint", env.Map{"STRICT_PORT": "8e1"}, env.ErrNumberKind},
+		{"integer into float", env.Map{"STRICT_RATIO": "1"}, env.ErrNumberKind},
+		{"hexadecimal float", env.Map{"STRICT_SCALE": "0x1p-2"}, env.ErrNumberKind},
+		{"infinity", env.Map{"STRICT_RATIO": "Inf"}, env.ErrNumberKind},
+	}
+
+	for _, testCase := range testCases {
+		var s strictStruct
+		err := env.NewDecoder(env.WithSource(testCase.environ), env.WithStrictTypes()).Unmarshal(&s)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%s: Expected error '%v' but got '%v'", testCase.name, testCase.expected, err)
+		}
+	}
+
+	var looseStruct strictStruct
+	err := env.NewDecoder(env.WithSource(env.Map{"STRICT_RATIO": "1"})).Unmarshal(&looseStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if looseStruct.Ratio != 1 {
+		t.Errorf("Expected field value to be '%v' but got '%v'", 1.0, looseStruct.Ratio)
+	}
+}
+
+func TestDecoderDefaults(t *testing.T) {
+	type defaultsStruct struct {
+		Host    string        `env:"BASELINE_HOST,default=localhost"`
+		Port    int           `env:"BASELINE_PORT,default=5432"`
+		Timeout time.Duration `env:"BASELINE_TIMEOUT"`
+		Name    string        `env:"BASELINE_NAME,required"`
+		Workers int           `env:"BASELINE_WORKERS"`
+	}
+
+	base := defaultsStruct{
+		Host:    "base.local",
+		Port:    6543,
+		Timeout: 5 * time.Second,
+		Name:    "base",
+	}
+	m := env.Map{"BASELINE_HOST": "env.local"}
+
+	var s defaultsStruct
+	err := env.NewDecoder(env.WithSource(m), env.WithDefaults(base)).Unmarshal(&s)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := defaultsStruct{
+		Host:    "env.local",
+		Port:    5432,
+		Timeout: 5 * time.Second,
+		Name:    "base",
+		Workers: 0,
+	}
+	if s != expected {
+		t.Errorf("Expected field values to be '%+v' but got '%+v'", expected, s)
+	}
+
+	base.Name = ""
+	err = env.NewDecoder(env.WithSource(m), env.WithDefaults(&base)).Unmarshal(&s)
+	if !errors.Is(err, env.ErrMissingRequired) {
+		t.Errorf("Expected error to be '%s' but got '%v'", env.ErrMissingRequired, err)
+	}
+
+	err = env.NewDecoder(env.WithSource(m), env.WithDefaults(struct{}{})).Unmarshal(&s)
+	if !errors.Is(err, env.ErrInvalidValue) {
+		t.Errorf("Expected error to be '%s' but got '%v'", env.ErrInvalidValue, err)
+	}
+}
+
+func TestDecoderBoolCollections(t *testing.T) {
+	m := env.Map{
+		"BOOL_SLICE":  "true,false,1,0,T,F",
+		"BOOL_MAP":    "a=true,b=0,c=FALSE,d=1",
+		"LOOSE_SLICE": "yes,off,1,false",
+		"LOOSE_MAP":   "a=on,b=N,c=true",
+	}
+
+	var boolStruct struct {
+		Slice []bool          `env:"BOOL_SLICE"`
+		Map   map[string]bool `env:"BOOL_MAP"`
+	}
+	err := env.NewDecoder(env.WithSource(m)).Unmarshal(&boolStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedSlice := []bool{true, false, true, false, true, false}
+	if !reflect.DeepEqual(boolStruct.Slice, expectedSlice) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedSlice, boolStruct.Slice)
+	}
+
+	expectedMap := map[string]bool{"a": true, "b": false, "c": false, "d": true}
+	if !reflect.DeepEqual(boolStruct.Map, expectedMap) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedMap, boolStruct.Map)
+	}
+
+	var looseStruct struct {
+		Slice []bool          `env:"LOOSE_SLICE"`
+		Map   map[string]bool `env:"LOOSE_MAP"`
+	}
+	err = env.NewDecoder(env.WithSource(m), env.WithLooseBools()).Unmarshal(&looseStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedSlice = []bool{true, false, true, false}
+	if !reflect.DeepEqual(looseStruct.Slice, expectedSlice) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedSlice, looseStruct.Slice)
+	}
+
+	expectedMap = map[string]bool{"a": true, "b": false, "c": true}
+	if !reflect.DeepEqual(looseStruct.Map, expectedMap) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedMap, looseStruct.Map)
+	}
+
+	err = env.NewDecoder(env.WithSource(m)).Unmarshal(&looseStruct)
+	if err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
+
+type ReusedInner struct {
+	X string `env:"REUSED_X"`
+}
+
+func TestDecoderDefaultsReused(t *testing.T) {
+	type outer struct {
+		*ReusedInner
+		List   []string          `env:"REUSED_LIST,append"`
+		Labels map[string]string `env:"REUSED_LABELS"`
+	}
+
+	list := make([]string, 1, 4)
+	list[0] = "base"
+	base := outer{
+		ReusedInner: &ReusedInner{X: "base"},
+		List:        list,
+		Labels:      map[string]string{"tier": "1"},
+	}
+	decoder := env.NewDecoder(env.WithSource(env.Map{
+		"REUSED_X":    "fromenv",
+		"REUSED_LIST": "a,b",
+	}), env.WithDefaults(base))
+
+	for i := 0; i < 2; i++ {
+		var s outer
+		err := decoder.Unmarshal(&s)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if s.ReusedInner == nil || s.X != "fromenv" {
+			t.Errorf("Expected field value to be '%s' but got '%v'", "fromenv", s.ReusedInner)
+		}
+
+		expectedList := []string{"base", "a", "b"}
+		if !reflect.DeepEqual(s.List, expectedList) {
+			t.Errorf("Expected field value to be '%v' but got '%v'", expectedList, s.List)
+		}
+
+		s.Labels["tier"] = "2"
+	}
+
+	if base.X != "base" {
+		t.Errorf("Expected baseline value to be '%s' but got '%s'", "base", base.X)
+	}
+
+	if base.List[:2][1] != "" {
+		t.Errorf("Expected baseline backing array to be unchanged but got '%v'", base.List[:2])
+	}
+
+	if base.Labels["tier"] != "1" {
+		t.Errorf("Expected baseline value to be '%s' but got '%s'", "1", base.Labels["tier"])
+	}
+}
+
+func TestDecoderInlineOptions(t *testing.T) {
+	type flags struct {
+		On    bool    `env:"on"`
+		Ratio float64 `env:"ratio;default=0.5"`
+	}
+	type pair struct {
+		Enabled bool
+		Debug   bool
+	}
+
+	m := env.Map{
+		"INLINE_FLAGS": "on=yes",
+		"INLINE_PAIR":  "on-off",
+	}
+
+	var inlineStruct struct {
+		Flags flags `env:"INLINE_FLAGS;inline"`
+		Pair  pair  `env:"INLINE_PAIR;inline;sep=-"`
+	}
+	decoder := env.NewDecoder(env.WithSource(m), env.WithLooseBools(), env.WithTagOptionSeparator(";"))
+	err := decoder.Unmarshal(&inlineStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedFlags := flags{On: true, Ratio: 0.5}
+	if inlineStruct.Flags != expectedFlags {
+		t.Errorf("Expected field value to be '%+v' but got '%+v'", expectedFlags, inlineStruct.Flags)
+	}
+
+	expectedPair := pair{Enabled: true, Debug: false}
+	if inlineStruct.Pair != expectedPair {
+		t.Errorf("Expected field value to be '%+v' but got '%+v'", expectedPair, inlineStruct.Pair)
+	}
+
+	m = env.Map{"INLINE_FLAGS": "on=true;ratio=1"}
+	decoder = env.NewDecoder(env.WithSource(m), env.WithStrictTypes(), env.WithTagOptionSeparator(";"))
+	err = decoder.Unmarshal(&inlineStruct)
+	if !errors.Is(err, env.ErrNumberKind) {
+		t.Errorf("Expected error to be '%s' but got '%v'", env.ErrNumberKind, err)
+	}
+}
@@ -0,0 +1,27 @@
+package env
+
+import (
+	"sync"
+)
+
+var (
+	defaultFuncsMu sync.RWMutex
+	defaultFuncs   = make(map[string]func() string)
+)
+
+// RegisterDefaultFunc registers fn under name for tags such as
+// `env:"RUN_ID,defaultFunc=name"`. When the variables of such a field are
+// missing, fn is called to produce its value. Registering a name again
+// replaces the previous function.
+func RegisterDefaultFunc(name string, fn func() string) {
+	defaultFuncsMu.Lock()
+	defer defaultFuncsMu.Unlock()
+	defaultFuncs[name] = fn
+}
+
+func lookupDefaultFunc(name string) (func() string, bool) {
+	defaultFuncsMu.RLock()
+	defer defaultFuncsMu.RUnlock()
+	fn, ok := defaultFuncs[name]
+	return fn, ok
+}
@@ -0,0 +1,101 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+type DefaultFuncStruct struct {
+	RunID string `env:"DEFAULT_FUNC_RUN_ID,defaultFunc=token"`
+}
+
+func TestRegisterDefaultFunc(t *testing.T) {
+	env.RegisterDefaultFunc("token", func() string {
+		return "fixed-token"
+	})
+
+	var defaultFuncStruct DefaultFuncStruct
+	err := env.Unmarshal(&defaultFuncStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if defaultFuncStruct.RunID != "fixed-token" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "fixed-token", defaultFuncStruct.RunID)
+	}
+
+	_ = os.Setenv("DEFAULT_FUNC_RUN_ID", "present")
+	defer os.Unsetenv("DEFAULT_FUNC_RUN_ID")
+
+	err = env.Unmarshal(&defaultFuncStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if defaultFuncStruct.RunID != "present" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "present", defaultFuncStruct.RunID)
+	}
+}
+
+func TestUnknownDefaultFunc(t *testing.T) {
+	var unknownStruct struct {
+		Value string `env:"DEFAULT_FUNC_UNKNOWN,defaultFunc=unknown"`
+	}
+	err := env.Unmarshal(&unknownStruct)
+	if !errors.Is(err, env.ErrUnknownDefaultFunc) {
+		t.Errorf("Expected error 'ErrUnknownDefaultFunc' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalDefaultFuncWithOtherFields(t *testing.T) {
+	env.RegisterDefaultFunc("workers", func() string {
+		return "4"
+	})
+	env.RegisterDefaultFunc("region", func() string {
+		return "eu-west-1"
+	})
+
+	m := map[string]string{
+		"MIXED_HOST":   "db.local",
+		"MIXED_REGION": "us-east-1",
+	}
+
+	var mixedStruct struct {
+		Host    string `env:"MIXED_HOST"`
+		Port    int    `env:"MIXED_PORT,default=5432"`
+		Workers int    `env:"MIXED_WORKERS,defaultFunc=workers"`
+		Region  string `env:"MIXED_REGION,defaultFunc=region"`
+
+		Cache struct {
+			Workers int    `env:"MIXED_CACHE_WORKERS,defaultFunc=workers"`
+			Host    string `env:"MIXED_CACHE_HOST,default=cache.local"`
+		}
+	}
+	err := env.UnmarshalMap(m, &mixedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if mixedStruct.Host != "db.local" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "db.local", mixedStruct.Host)
+	}
+
+	if mixedStruct.Port != 5432 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 5432, mixedStruct.Port)
+	}
+
+	if mixedStruct.Workers != 4 || mixedStruct.Cache.Workers != 4 {
+		t.Errorf("Expected field values to be '%d' but got '%d' and '%d'", 4, mixedStruct.Workers, mixedStruct.Cache.Workers)
+	}
+
+	if mixedStruct.Region != "us-east-1" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "us-east-1", mixedStruct.Region)
+	}
+
+	if mixedStruct.Cache.Host != "cache.local" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "cache.local", mixedStruct.Cache.Host)
+	}
+}
@@ -0,0 +1,156 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldInfo describes a struct field tagged with "env".
+type FieldInfo struct {
+	// Field is the Go field name. Fields of nested structures are
+	// prefixed with the names of the enclosing fields, separated by dots.
+	Field string
+
+	// Type is the Go type of the field.
+	Type reflect.Type
+
+	// Keys are the environment variables looked up for the field, in
+	// order. The first one is the canonical key, the others are aliases.
+	Keys []string
+
+	// Default is the value used when none of the keys are set.
+	Default string
+
+	// Required reports whether the field must have a value.
+	Required bool
+
+	// Constraints maps the validation options of the tag, such as "min"
+	// and "max", to their values. Options without a value, such as
+	// "notempty", map to an empty string.
+	Constraints map[string]string
+}
+
+// Key returns the canonical key of the field, the first of its keys.
+func (f FieldInfo) Key() string {
+	if len(f.Keys) == 0 {
+		return ""
+	}
+	return f.Keys[0]
+}
+
+// Aliases returns the keys of the field looked up after the canonical key.
+func (f FieldInfo) Aliases() []string {
+	if len(f.Keys) < 2 {
+		return nil
+	}
+	return f.Keys[1:]
+}
+
+// Describe returns the metadata of the fields tagged with "env" in the
+// structure v or pointed to by v, in declaration order.
+//
+// If v is not a structure or a pointer to a structure, Describe returns
+// ErrInvalidValue. If fields tagged with "env" are not exported, Describe
+// returns ErrUnexportedField.
+func Describe(v interface{}) ([]FieldInfo, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, ErrInvalidValue
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrInvalidValue
+	}
+
+	return describe(rv.Type(), "", "", make(map[reflect.Type]bool))
+}
+
+// describe returns the fields of t. The structure types being described
+// are in visiting, so that embedded pointers to them are skipped.
+func describe(t reflect.Type, path, prefix string, visiting map[reflect.Type]bool) ([]FieldInfo, error) {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var fields []FieldInfo
+
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+		fieldPath := path + typeField.Name
+
+		nestedType := typeField.Type
+		if isEmbeddedStructPtr(typeField) {
+			nestedType = nestedType.Elem()
+		}
+		if nestedType.Kind() == reflect.Struct && typeField.PkgPath == "" && !isInline(typeField) && !visiting[nestedType] {
+			nested, err := describe(nestedType, fieldPath+".", prefix+typeField.Tag.Get("envPrefix"), visiting)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+		}
+
+		tag := typeField.Tag.Get("env")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		if typeField.PkgPath != "" {
+			return nil, ErrUnexportedField
+		}
+
+		envTag := parseTag(tag).withPrefix(prefix)
+		fields = append(fields, FieldInfo{
+			Field:       fieldPath,
+			Type:        typeField.Type,
+			Keys:        envTag.Keys,
+			Default:     envTag.Default,
+			Required:    envTag.Required,
+			Constraints: constraints(envTag),
+		})
+	}
+
+	return fields, nil
+}
+
+// constraints returns the validation options of t, or nil if it has none.
+func constraints(t tag) map[string]string {
+	c := make(map[string]string)
+	if t.NotEmpty {
+		c["notempty"] = ""
+	}
+	if len(t.OneOf) > 0 {
+		c["oneof"] = strings.Join(t.OneOf, "|")
+	}
+	if len(t.AnyOf) > 0 {
+		c["anyof"] = strings.Join(t.AnyOf, "|")
+	}
+	if len(t.Schemes) > 0 {
+		c["schemes"] = strings.Join(t.Schemes, "|")
+	}
+	if t.Bool01 {
+		c["bool01"] = ""
+	}
+	if t.NonNeg {
+		c["nonneg"] = ""
+	}
+	if t.Min != "" {
+		c["min"] = t.Min
+	}
+	if t.Max != "" {
+		c["max"] = t.Max
+	}
+	if t.MaxLen != "" {
+		c["maxlen"] = t.MaxLen
+	}
+	if t.MaxTotal != "" {
+		c["maxtotal"] = t.MaxTotal
+	}
+
+	if len(c) == 0 {
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,157 @@
+package env_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+type DescribeStruct struct {
+	Home string `env:"HOME,required"`
+
+	Server struct {
+		Port    int           `env:"PORT,APP_PORT,min=1,max=65535,default=8080"`
+		Timeout time.Duration `env:"TIMEOUT,secfloat"`
+	}
+
+	Extra string
+}
+
+func TestDescribe(t *testing.T) {
+	fields, err := env.Describe(&DescribeStruct{})
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []env.FieldInfo{
+		{
+			Field:    "Home",
+			Type:     reflect.TypeOf(""),
+			Keys:     []string{"HOME"},
+			Required: true,
+		},
+		{
+			Field:   "Server.Port",
+			Type:    reflect.TypeOf(0),
+			Keys:    []string{"PORT", "APP_PORT"},
+			Default: "8080",
+			Constraints: map[string]string{
+				"min": "1",
+				"max": "65535",
+			},
+		},
+		{
+			Field: "Server.Timeout",
+			Type:  reflect.TypeOf(time.Duration(0)),
+			Keys:  []string{"TIMEOUT"},
+		},
+	}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields to be '%+v' but got '%+v'", expected, fields)
+	}
+}
+
+func TestDescribeCanonicalKey(t *testing.T) {
+	var canonicalStruct struct {
+		Port int    `env:"PORT,APP_PORT,LEGACY_PORT"`
+		Host string `env:"HOST"`
+	}
+	fields, err := env.Describe(&canonicalStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if len(fields) != 2 {
+		t.Fatalf("Expected %d fields but got %d", 2, len(fields))
+	}
+
+	if key := fields[0].Key(); key != "PORT" {
+		t.Errorf("Expected canonical key to be '%s' but got '%s'", "PORT", key)
+	}
+
+	expected := []string{"APP_PORT", "LEGACY_PORT"}
+	if aliases := fields[0].Aliases(); !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("Expected aliases to be '%q' but got '%q'", expected, aliases)
+	}
+
+	if key := fields[1].Key(); key != "HOST" {
+		t.Errorf("Expected canonical key to be '%s' but got '%s'", "HOST", key)
+	}
+
+	if aliases := fields[1].Aliases(); aliases != nil {
+		t.Errorf("Expected no aliases but got '%q'", aliases)
+	}
+
+	data, err := env.Marshal(&canonicalStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if expected := "PORT=0\nHOST=\n"; string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+}
+
+func TestDescribeSelfEmbeddedPointer(t *testing.T) {
+	fields, err := env.Describe(SelfEmbeddedStruct{})
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []env.FieldInfo{
+		{
+			Field: "X",
+			Type:  reflect.TypeOf(""),
+			Keys:  []string{"SELF_X"},
+		},
+	}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields to be '%+v' but got '%+v'", expected, fields)
+	}
+}
+
+func TestDescribeEmbeddedPointer(t *testing.T) {
+	fields, err := env.Describe(EmbeddedPointerStruct{})
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []env.FieldInfo{
+		{
+			Field: "EmbeddedBase.Name",
+			Type:  reflect.TypeOf(""),
+			Keys:  []string{"EMBEDDED_NAME"},
+		},
+		{
+			Field:   "EmbeddedBase.Port",
+			Type:    reflect.TypeOf(0),
+			Keys:    []string{"EMBEDDED_PORT"},
+			Default: "8080",
+		},
+		{
+			Field: "Database.Host",
+			Type:  reflect.TypeOf(""),
+			Keys:  []string{"EMBEDDED_DB_HOST"},
+		},
+	}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields to be '%+v' but got '%+v'", expected, fields)
+	}
+}
+
+func TestDescribeInvalid(t *testing.T) {
+	_, err := env.Describe("string")
+	if err != env.ErrInvalidValue {
+		t.Errorf("Expected error 'ErrInvalidValue' but got '%s'", err)
+	}
+
+	_, err = env.Describe(&UnexportedStruct{})
+	if err != env.ErrUnexportedField {
+		t.Errorf("Expected error 'ErrUnexportedField' but got '%s'", err)
+	}
+}
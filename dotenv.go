@@ -0,0 +1,215 @@
+package env
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrInvalidLine returned when a line of a .env file is not a comment
+	// and not in the form key=value.
+	ErrInvalidLine = errors.New("line must be in the form key=value")
+
+	// ErrInvalidKey returned when a key of a .env file is not a valid shell
+	// identifier, made of letters, digits and underscores and not starting
+	// with a digit.
+	ErrInvalidKey = errors.New("key must be a valid shell identifier")
+)
+
+// byteOrderMark is the UTF-8 byte order mark some editors write at the
+// start of a file.
+const byteOrderMark = "\ufeff"
+
+// ParseOption configures the reading of .env files by Parse and ReadFile,
+// and their writing by Marshal.
+type ParseOption func(*dotEnvParser)
+
+// WithCommentPrefix sets the prefixes of comment lines, such as ";" or
+// "//", replacing the default "#".
+func WithCommentPrefix(prefixes ...string) ParseOption {
+	return func(p *dotEnvParser) {
+		p.commentPrefixes = prefixes
+	}
+}
+
+// WithInlineComments makes a comment prefix preceded by whitespace end an
+// unquoted value, and ignores anything after the closing quote of a quoted
+// value. Without it, values such as `a #b` keep the comment prefix.
+func WithInlineComments() ParseOption {
+	return func(p *dotEnvParser) {
+		p.inlineComments = true
+	}
+}
+
+// WithLaxKeys accepts keys that are not valid shell identifiers, such as
+// "1FOO" or "my-key", when reading them and writing them with Marshal.
+func WithLaxKeys() ParseOption {
+	return func(p *dotEnvParser) {
+		p.laxKeys = true
+	}
+}
+
+type dotEnvParser struct {
+	commentPrefixes []string
+	inlineComments  bool
+	laxKeys         bool
+}
+
+// ReadFile reads the .env file named filename and returns its variables.
+func ReadFile(filename string, opts ...ParseOption) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f, opts...)
+}
+
+// AutoLoad is like UnmarshalWithFile with the .env file of the working
+// directory.
+func AutoLoad(v interface{}) error {
+	return UnmarshalWithFile(".env", v)
+}
+
+// UnmarshalWithFile stores the variables of os.Environ at the value pointed
+// to by v, with the variables of the .env file named filename if it exists.
+// Variables of os.Environ take precedence over those of the file. It
+// returns the errors of ReadFile and Unmarshal.
+func UnmarshalWithFile(filename string, v interface{}) error {
+	m, err := ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	es := environToEnvSet(os.Environ())
+	for key, value := range m {
+		if _, ok := es.values[key]; !ok {
+			es.values[key] = value
+		}
+	}
+	return NewDecoder().unmarshal(es, v)
+}
+
+// Parse reads variables in the .env format from r.
+//
+// Each line is in the form key=value. A leading UTF-8 byte order mark is
+// ignored. Blank lines and lines starting with a comment prefix, "#" by
+// default, are ignored. Whitespace around keys and values is trimmed, and
+// values enclosed in single or double quotes are unquoted.
+//
+// If a line is not in the form key=value, Parse returns an error wrapping
+// ErrInvalidLine. If a key is not a valid shell identifier, Parse returns
+// an error wrapping ErrInvalidKey, unless WithLaxKeys is used.
+func Parse(r io.Reader, opts ...ParseOption) (map[string]string, error) {
+	p := &dotEnvParser{commentPrefixes: []string{"#"}}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	m := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if n == 1 {
+			line = strings.TrimPrefix(line, byteOrderMark)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || p.isComment(line) {
+			continue
+		}
+
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: %w", n, ErrInvalidLine)
+		}
+
+		key = strings.TrimSpace(key)
+		if !p.laxKeys && !isValidKey(key) {
+			return nil, fmt.Errorf("line %d: %s: %w", n, key, ErrInvalidKey)
+		}
+
+		value = strings.TrimSpace(value)
+		if p.inlineComments {
+			value = strings.TrimSpace(p.stripInlineComment(value))
+		}
+
+		m[key] = unquote(value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// isComment reports whether line starts with a comment prefix.
+func (p *dotEnvParser) isComment(line string) bool {
+	for _, prefix := range p.commentPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripInlineComment removes a trailing comment from value.
+func (p *dotEnvParser) stripInlineComment(value string) string {
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			return value[:end+2]
+		}
+		return value
+	}
+
+	for i := 1; i < len(value); i++ {
+		if value[i-1] != ' ' && value[i-1] != '\t' {
+			continue
+		}
+		for _, prefix := range p.commentPrefixes {
+			if strings.HasPrefix(value[i:], prefix) {
+				return value[:i]
+			}
+		}
+	}
+	return value
+}
+
+// isValidKey reports whether key is a valid shell identifier.
+func isValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c == '_', 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// splitKeyValue splits s at the first "=", so values may contain "=".
+func splitKeyValue(s string) (string, string, bool) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// unquote removes matching single or double quotes around s.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
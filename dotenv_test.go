@@ -0,0 +1,284 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+func TestParse(t *testing.T) {
+	content := `
+# comment
+HOME=/home/test
+DATA=a=b=c
+  SPACED  =  value  
+DOUBLE="quoted value"
+SINGLE='quoted value'
+EMPTY=
+`
+
+	m, err := env.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := map[string]string{
+		"HOME":   "/home/test",
+		"DATA":   "a=b=c",
+		"SPACED": "value",
+		"DOUBLE": "quoted value",
+		"SINGLE": "quoted value",
+		"EMPTY":  "",
+	}
+
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected variables to be '%v' but got '%v'", expected, m)
+	}
+}
+
+func TestParseByteOrderMark(t *testing.T) {
+	m, err := env.Parse(strings.NewReader("\ufeffFIRST=1\nSECOND=2\n"))
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := map[string]string{
+		"FIRST":  "1",
+		"SECOND": "2",
+	}
+
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected variables to be '%v' but got '%v'", expected, m)
+	}
+}
+
+func TestParseCommentPrefix(t *testing.T) {
+	content := "; comment\n// comment\nCOLOR=#ff8800\nURL=http://host/#anchor\n"
+
+	m, err := env.Parse(strings.NewReader(content), env.WithCommentPrefix(";", "//"))
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := map[string]string{
+		"COLOR": "#ff8800",
+		"URL":   "http://host/#anchor",
+	}
+
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected variables to be '%v' but got '%v'", expected, m)
+	}
+}
+
+func TestParseInlineComments(t *testing.T) {
+	content := "PORT=8080 # http port\nCOLOR=#ff8800\nNAME=\"a # b\" # quoted\n"
+
+	m, err := env.Parse(strings.NewReader(content), env.WithInlineComments())
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := map[string]string{
+		"PORT":  "8080",
+		"COLOR": "#ff8800",
+		"NAME":  "a # b",
+	}
+
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected variables to be '%v' but got '%v'", expected, m)
+	}
+
+	m, err = env.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if m["PORT"] != "8080 # http port" {
+		t.Errorf("Expected value to be '%s' but got '%s'", "8080 # http port", m["PORT"])
+	}
+}
+
+func TestParseInvalidKey(t *testing.T) {
+	content := "FOO=1\n1FOO=2\n"
+
+	_, err := env.Parse(strings.NewReader(content))
+	if !errors.Is(err, env.ErrInvalidKey) {
+		t.Errorf("Expected error 'ErrInvalidKey' but got '%v'", err)
+	}
+
+	if err != nil && err.Error() != "line 2: 1FOO: key must be a valid shell identifier" {
+		t.Errorf("Expected error to report line 2 and key '1FOO' but got '%s'", err)
+	}
+
+	m, err := env.Parse(strings.NewReader(content), env.WithLaxKeys())
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if m["1FOO"] != "2" {
+		t.Errorf("Expected value to be '%s' but got '%s'", "2", m["1FOO"])
+	}
+}
+
+func TestParseInvalidLine(t *testing.T) {
+	_, err := env.Parse(strings.NewReader("HOME=/home/test\nINVALID\n"))
+	if !errors.Is(err, env.ErrInvalidLine) {
+		t.Errorf("Expected error 'ErrInvalidLine' but got '%v'", err)
+	}
+
+	if err != nil && !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Errorf("Expected error to report line 2 but got '%s'", err)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(filename, []byte("DATA=a=b=c\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := env.ReadFile(filename)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if m["DATA"] != "a=b=c" {
+		t.Errorf("Expected value to be '%s' but got '%s'", "a=b=c", m["DATA"])
+	}
+
+	_, err = env.ReadFile(filepath.Join(t.TempDir(), "missing.env"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected error 'ErrNotExist' but got '%v'", err)
+	}
+}
+
+func TestParseQuotedValues(t *testing.T) {
+	data := "TIMEOUT=\"30s\"\nWORKERS='4'\nRATIO = \"0.5\"\n"
+	m, err := env.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	var quotedStruct struct {
+		Timeout time.Duration `env:"TIMEOUT"`
+		Workers int           `env:"WORKERS"`
+		Ratio   float64       `env:"RATIO"`
+	}
+	err = env.UnmarshalMap(m, &quotedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if quotedStruct.Timeout != 30*time.Second {
+		t.Errorf("Expected field value to be '%s' but got '%s'", 30*time.Second, quotedStruct.Timeout)
+	}
+
+	if quotedStruct.Workers != 4 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 4, quotedStruct.Workers)
+	}
+
+	if quotedStruct.Ratio != 0.5 {
+		t.Errorf("Expected field value to be '%g' but got '%g'", 0.5, quotedStruct.Ratio)
+	}
+}
+
+func TestAutoLoad(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type autoLoadStruct struct {
+		Host string `env:"AUTO_LOAD_HOST,default=localhost"`
+		Port int    `env:"AUTO_LOAD_PORT,default=5432"`
+	}
+
+	var withoutFile autoLoadStruct
+	err = env.AutoLoad(&withoutFile)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if withoutFile.Host != "localhost" || withoutFile.Port != 5432 {
+		t.Errorf("Expected field values to be '%s' and '%d' but got '%s' and '%d'", "localhost", 5432, withoutFile.Host, withoutFile.Port)
+	}
+
+	err = os.WriteFile(".env", []byte("AUTO_LOAD_HOST=db.local\nAUTO_LOAD_PORT=6543\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.Setenv("AUTO_LOAD_PORT", "7000")
+	defer os.Unsetenv("AUTO_LOAD_PORT")
+
+	var withFile autoLoadStruct
+	err = env.AutoLoad(&withFile)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if withFile.Host != "db.local" || withFile.Port != 7000 {
+		t.Errorf("Expected field values to be '%s' and '%d' but got '%s' and '%d'", "db.local", 7000, withFile.Host, withFile.Port)
+	}
+}
+
+func TestUnmarshalWithFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.env")
+	err := os.WriteFile(filename, []byte("WITH_FILE_HOST=db.local\nWITH_FILE_PORT=6543\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.Setenv("WITH_FILE_HOST", "override.local")
+	defer os.Unsetenv("WITH_FILE_HOST")
+
+	var withFileStruct struct {
+		Host    string `env:"WITH_FILE_HOST"`
+		Port    int    `env:"WITH_FILE_PORT"`
+		Workers int    `env:"WITH_FILE_WORKERS,default=4"`
+	}
+	err = env.UnmarshalWithFile(filename, &withFileStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if withFileStruct.Host != "override.local" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "override.local", withFileStruct.Host)
+	}
+
+	if withFileStruct.Port != 6543 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 6543, withFileStruct.Port)
+	}
+
+	if withFileStruct.Workers != 4 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 4, withFileStruct.Workers)
+	}
+
+	err = env.UnmarshalWithFile(filepath.Join(t.TempDir(), "missing.env"), &withFileStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	err = os.WriteFile(filename, []byte("INVALID\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.UnmarshalWithFile(filename, &withFileStruct)
+	if !errors.Is(err, env.ErrInvalidLine) {
+		t.Errorf("Expected error 'ErrInvalidLine' but got '%v'", err)
+	}
+}
@@ -0,0 +1,17 @@
+package env
+
+import (
+	"time"
+)
+
+// SumDurations returns the total of durations, such as the delays of a
+// backoff schedule parsed from `100ms,500ms,2s`. Like the addition of
+// durations, the total wraps when it overflows; the maxtotal tag option
+// bounds the sum without wrapping.
+func SumDurations(durations []time.Duration) time.Duration {
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return total
+}
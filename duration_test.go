@@ -0,0 +1,32 @@
+package env_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+func TestSumDurations(t *testing.T) {
+	var backoffStruct struct {
+		Backoff []time.Duration `env:"BACKOFF"`
+	}
+	err := env.UnmarshalMap(map[string]string{"BACKOFF": "100ms,500ms,2s"}, &backoffStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []time.Duration{100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second}
+	if !reflect.DeepEqual(backoffStruct.Backoff, expected) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expected, backoffStruct.Backoff)
+	}
+
+	if total := env.SumDurations(backoffStruct.Backoff); total != 2600*time.Millisecond {
+		t.Errorf("Expected total to be '%s' but got '%s'", 2600*time.Millisecond, total)
+	}
+
+	if total := env.SumDurations(nil); total != 0 {
+		t.Errorf("Expected total to be '%s' but got '%s'", time.Duration(0), total)
+	}
+}
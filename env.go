@@ -1,11 +1,17 @@
 package env
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"math"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,13 +25,323 @@ var (
 
 	// ErrUnsupportedType returned when a field with tag "env" is unsupported.
 	ErrUnsupportedType = errors.New("field is an unsupported type")
+
+	// ErrUnknownKey returned by strict unmarshaling when variables are not
+	// used by any field.
+	ErrUnknownKey = errors.New("environment variable is not used by any field")
+
+	// ErrMissingRequired returned when a field tagged as required has no
+	// environment variable and no default value.
+	ErrMissingRequired = errors.New("required environment variable is missing")
+
+	// ErrUnknownDefaultFunc returned when a tag names a default function
+	// that is not registered.
+	ErrUnknownDefaultFunc = errors.New("default function is not registered")
+
+	// ErrUnknownTransform returned when the transform tag option names a
+	// transform that is not registered.
+	ErrUnknownTransform = errors.New("transform is not registered")
+
+	// ErrOutOfRange returned when a value or its length is outside the
+	// bounds set by the min and max tag options.
+	ErrOutOfRange = errors.New("value is out of range")
+
+	// ErrInvalidMapEntry returned when an entry of a map value has no
+	// key and value separator.
+	ErrInvalidMapEntry = errors.New("map entry must be in the form key=value")
+
+	// ErrNotAllowed returned when a value is not one of the values listed
+	// by the oneof tag option.
+	ErrNotAllowed = errors.New("value is not allowed")
+
+	// ErrInvalidTag returned when a tag option has an invalid value.
+	ErrInvalidTag = errors.New("tag option is invalid")
+
+	// ErrEmptyValue returned when a field tagged as notempty has an empty
+	// value.
+	ErrEmptyValue = errors.New("environment variable must not be empty")
+
+	// ErrDuplicateKey returned by a Decoder created with
+	// WithErrorOnDuplicateKeys when fields share a key.
+	ErrDuplicateKey = errors.New("key is used by another field")
+
+	// ErrUntaggedField returned by a Decoder created with WithRequireTag
+	// when exported fields have no tag "env".
+	ErrUntaggedField = errors.New("field has no env tag")
+
+	// ErrInvalidLength returned when a value decoded into an array does not
+	// have the length of the array.
+	ErrInvalidLength = errors.New("value does not have the length of the array")
+
+	// ErrNumberKind returned by a Decoder created with WithStrictTypes when
+	// an integer field has a float value, or a float field has a value
+	// without a fraction or exponent.
+	ErrNumberKind = errors.New("number does not have the kind of the field")
 )
 
-type envSet map[string]string
+// DefaultSentinel is a value of an environment variable that is treated
+// as if the variable was missing, so the default of the field is used. It
+// helps when a variable cannot be unset.
+const DefaultSentinel = "__default__"
+
+// envSet holds the variables of an unmarshal and records the keys used by
+// its fields.
+type envSet struct {
+	values   map[string]string
+	used     map[string]bool
+	catchAll []catchAll
+
+	// owners maps the keys of the decoded fields to their dotted paths,
+	// when duplicate keys are errors.
+	owners map[string]string
+
+	// initializers are the decoded structures implementing Initializer,
+	// nested structures first.
+	initializers []Initializer
+
+	// validators are the decoded structures implementing Validator, in
+	// the same order.
+	validators []validator
+
+	// visiting holds the structure types being decoded, so that embedded
+	// pointers to them are not allocated and decoded again.
+	visiting map[reflect.Type]bool
+}
+
+// validator is a decoded structure implementing Validator and its dotted
+// path, used as the context of its errors.
+type validator struct {
+	path      string
+	validator Validator
+}
+
+// catchAll is a field tagged with "*" or "PREFIX*" that receives the
+// variables with the prefix not used by other fields.
+type catchAll struct {
+	prefix string
+	field  reflect.Value
+}
+
+func newEnvSet(values map[string]string) *envSet {
+	return &envSet{values: values, used: make(map[string]bool), visiting: make(map[reflect.Type]bool)}
+}
+
+// Initializer is implemented by structures that complete their
+// initialization after Unmarshal has set their fields, for example to
+// derive fields from others. AfterUnmarshal is called with a pointer
+// receiver on the target structure and its nested structures, nested
+// structures first. An error returned by AfterUnmarshal is returned by
+// Unmarshal.
+type Initializer interface {
+	AfterUnmarshal() error
+}
+
+var initializerType = reflect.TypeOf((*Initializer)(nil)).Elem()
+
+// Validator is implemented by structures that validate their fields once
+// they are set. Validate is called like AfterUnmarshal, after
+// AfterUnmarshal has been called on every structure. An error returned by
+// Validate is returned by Unmarshal, prefixed with the dotted path of a
+// nested structure or the name of the type of the target structure.
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// taggedTypes caches whether a structure type has tagged fields.
+var taggedTypes sync.Map
+
+// hasTaggedFields reports whether the structure type t or any of its
+// exported nested structures has fields tagged with "env" or implements
+// Initializer or Validator.
+func hasTaggedFields(t reflect.Type) bool {
+	if tagged, ok := taggedTypes.Load(t); ok {
+		return tagged.(bool)
+	}
+
+	tagged := taggedFields(t, make(map[reflect.Type]bool))
+	taggedTypes.Store(t, tagged)
+	return tagged
+}
+
+// taggedFields implements hasTaggedFields. Types in visiting are being
+// checked already, so structures embedding pointers to themselves end.
+func taggedFields(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	if reflect.PtrTo(t).Implements(initializerType) || reflect.PtrTo(t).Implements(validatorType) {
+		return true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if tag, ok := field.Tag.Lookup("env"); ok && tag != "-" {
+			return true
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && taggedFields(field.Type, visiting) {
+			return true
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct && taggedFields(field.Type.Elem(), visiting) {
+			return true
+		}
+	}
+	return false
+}
 
 type tag struct {
-	Key     string
-	Default string
+	Keys     []string
+	Default  string
+	Required bool
+
+	// HasDefault reports whether the tag has a default, so that an empty
+	// default is distinguished from none.
+	HasDefault bool
+
+	// DefaultFunc names the registered function returning the default.
+	DefaultFunc string
+
+	// Transform names the registered function modifying the field once
+	// it is set.
+	Transform string
+
+	// NotEmpty reports whether an empty value, or a slice or map without
+	// elements, is an error.
+	NotEmpty bool
+
+	// OneOf lists the allowed values, separated by "|" in the tag.
+	OneOf []string
+
+	// Bool01 reports whether an integer value must be 0 or 1.
+	Bool01 bool
+
+	// NonNeg reports whether a number or duration must not be negative.
+	NonNeg bool
+
+	// Msg replaces the text of missing, empty and validation errors.
+	Msg string
+
+	// Min and Max bound the value of a number, or the length of a string,
+	// slice, array or map.
+	Min string
+	Max string
+
+	// Concat lists the variables joined with Sep to form the value.
+	Concat []string
+
+	// Sep separates the elements of a slice or map value, "," by default.
+	Sep string
+
+	// TrimEmpty reports whether empty slice or map elements are dropped.
+	TrimEmpty bool
+
+	// Negate reports whether a bool value is inverted, so that a field
+	// such as CacheEnabled can be bound to a variable such as
+	// DISABLE_CACHE.
+	Negate bool
+
+	// Bools maps lower case values to the bool values they parse as,
+	// before strconv.ParseBool is tried. It is set from the options of
+	// the Decoder.
+	Bools map[string]bool
+
+	// EchoTrue reports whether a bool value equal to its key regardless of
+	// case, such as MODE=MODE, is true.
+	EchoTrue bool
+
+	// NumericBool reports whether a bool value is an integer, true unless
+	// it is zero.
+	NumericBool bool
+
+	// Inline reports whether a structure is decoded from a single value
+	// of entries such as "host=localhost;port=5432", keyed by the tags of
+	// its fields.
+	Inline bool
+
+	// StrictTypes reports whether number values must have the kind of the
+	// field, set by WithStrictTypes.
+	StrictTypes bool
+
+	// Secret reports whether the value is masked by MarshalJSON.
+	Secret bool
+
+	// File reports whether the value is the name of a file whose content
+	// is the value of the field.
+	File bool
+
+	// KeepNewline reports whether a trailing newline of a file read for
+	// the field is kept.
+	KeepNewline bool
+
+	// MaxLen is the maximum number of elements of a slice, checked
+	// before the elements are parsed.
+	MaxLen string
+
+	// AnyOf are regular expressions, one of which the value must match.
+	AnyOf []string
+
+	// Schemes are the allowed schemes of a URL, if any.
+	Schemes []string
+
+	// MaxTotal is the maximum sum of the elements of a slice of durations.
+	MaxTotal string
+
+	// Append reports whether slice elements are appended to the elements
+	// the field already has instead of replacing them.
+	Append bool
+
+	// KVSep separates the key and value of a map entry, "=" by default.
+	KVSep string
+
+	// Base64 reports whether a string value is base64 encoded.
+	Base64 bool
+
+	// Lower and Upper report whether a string value is converted to lower
+	// or upper case.
+	Lower bool
+	Upper bool
+
+	// Percent reports whether a float value may be a percentage.
+	Percent bool
+
+	// Base is the base of an integer value, 10 by default. A base of 0
+	// detects the base from the prefix of the value, such as "0x".
+	Base string
+
+	// Hex reports whether an integer value is hexadecimal, with an
+	// optional "0x" or "#" prefix for unsigned integers, like "ff8800", or
+	// a byte array is hex encoded.
+	Hex bool
+
+	// SI reports whether an integer value may have a K, M or G suffix.
+	SI bool
+
+	// SecFloat reports whether a time.Duration value is a float number of
+	// seconds.
+	SecFloat bool
+
+	// Clock reports whether a time.Duration value is given as HH:MM:SS or
+	// MM:SS.
+	Clock bool
+
+	// Unit is the unit of a time.Duration value given as an integer, such
+	// as "s" or "ms".
+	Unit string
+
+	// Layouts are the layouts tried in order to parse a time.Time value.
+	// They may be names of the layouts of package time, such as
+	// "RFC3339".
+	Layouts []string
+
+	// TZ is the name of the location of a time.Time value whose layout
+	// has no time zone, UTC by default.
+	TZ string
 }
 
 // Unmarshal parses os.Environ and stores the result at the value
@@ -39,21 +355,61 @@ type tag struct {
 //
 // If the field is of an unsupported type, Unmarshal returns
 // ErrUnsupportedType.
+//
+// If parsing a value fails, Unmarshal returns an error prefixed with the
+// key of the variable.
+//
+// If a field tagged as required has neither a value nor a default,
+// Unmarshal returns an error wrapping ErrMissingRequired. If a field tagged
+// as notempty has an empty value, Unmarshal returns an error wrapping
+// ErrEmptyValue.
 func Unmarshal(v interface{}) error {
-	es := environToEnvSet(os.Environ())
-	return unmarshal(es, v)
+	return NewDecoder().Unmarshal(v)
+}
+
+// UnmarshalEnviron parses environ, a list of "key=value" strings in the
+// form of os.Environ, and stores the result at the value pointed to by v.
+// Entries without "=" are ignored. It returns the same errors as Unmarshal.
+func UnmarshalEnviron(environ []string, v interface{}) error {
+	es := environToEnvSet(environ)
+	return NewDecoder().unmarshal(es, v)
+}
+
+// UnmarshalMap stores the variables of m at the value pointed to by v. It
+// returns the same errors as Unmarshal.
+func UnmarshalMap(m map[string]string, v interface{}) error {
+	return NewDecoder().unmarshal(newEnvSet(m), v)
+}
+
+// UnmarshalStrictFromMap is like UnmarshalMap but also returns an error
+// wrapping ErrUnknownKey if m has keys not used by any field. Keys stored in
+// a catch-all map are used.
+func UnmarshalStrictFromMap(m map[string]string, v interface{}) error {
+	es := newEnvSet(m)
+	err := NewDecoder().unmarshal(es, v)
+	if err != nil {
+		return err
+	}
+
+	if unused := es.unused(); len(unused) > 0 {
+		return fmt.Errorf("%s: %w", strings.Join(unused, ", "), ErrUnknownKey)
+	}
+	return nil
 }
 
-func environToEnvSet(environ []string) envSet {
-	m := make(envSet, len(environ))
+func environToEnvSet(environ []string) *envSet {
+	m := make(map[string]string, len(environ))
 	for _, v := range environ {
-		parts := strings.SplitN(v, "=", 2)
-		m[parts[0]] = parts[1]
+		key, value, ok := splitKeyValue(v)
+		if !ok {
+			continue
+		}
+		m[key] = value
 	}
-	return m
+	return newEnvSet(m)
 }
 
-func unmarshal(es envSet, v interface{}) error {
+func (d *Decoder) unmarshal(es *envSet, v interface{}) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return ErrInvalidValue
@@ -64,26 +420,112 @@ func unmarshal(es envSet, v interface{}) error {
 		return ErrInvalidValue
 	}
 
+	if d.requireTag {
+		if untagged := untaggedFields(rv.Type(), ""); len(untagged) > 0 {
+			return fmt.Errorf("%s: %w", strings.Join(untagged, ", "), ErrUntaggedField)
+		}
+	}
+
+	if d.errorOnDuplicateKeys {
+		if err := d.ownKeys(es, rv.Type(), "", ""); err != nil {
+			return err
+		}
+	}
+
+	if d.defaults != nil {
+		base := reflect.Indirect(reflect.ValueOf(d.defaults))
+		if !base.IsValid() || base.Type() != rv.Type() {
+			return fmt.Errorf("defaults of type %T: %w", d.defaults, ErrInvalidValue)
+		}
+		rv.Set(deepCopy(base))
+	}
+
+	var err error
+	if fields, ok := stringPlan(rv.Type()); ok && d.canUsePlan() {
+		err = d.decodePlan(es, rv, fields)
+	} else {
+		err = d.decode(es, rv, "", "")
+	}
+	if err != nil {
+		return err
+	}
+
+	es.fillCatchAll()
+
+	for _, initializer := range es.initializers {
+		err := initializer.AfterUnmarshal()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, v := range es.validators {
+		err := v.validator.Validate()
+		if err == nil {
+			continue
+		}
+
+		context := v.path
+		if context == "" {
+			context = rv.Type().Name()
+		}
+		if context == "" {
+			return err
+		}
+		return fmt.Errorf("%s: %w", context, err)
+	}
+	return nil
+}
+
+// decode stores the variables of es in the fields of the structure rv and
+// its nested structures. The names of the fields of rv are prefixed with
+// path in errors, and their keys with prefix.
+func (d *Decoder) decode(es *envSet, rv reflect.Value, path, prefix string) error {
 	t := rv.Type()
+	es.visiting[t] = true
+	defer delete(es.visiting, t)
 
 	for i := 0; i < t.NumField(); i++ {
 		valueField := rv.Field(i)
+		typeField := t.Field(i)
 		switch valueField.Kind() {
 		case reflect.Struct:
 			if !valueField.Addr().CanInterface() {
 				continue
 			}
 
-			iface := valueField.Addr().Interface()
-			err := unmarshal(es, iface)
+			if !hasTaggedFields(valueField.Type()) || d.isInline(typeField) {
+				break
+			}
+
+			err := d.decode(es, valueField, path+typeField.Name+".", prefix+typeField.Tag.Get("envPrefix"))
+			if err != nil {
+				return err
+			}
+		case reflect.Ptr:
+			if !isEmbeddedStructPtr(typeField) {
+				break
+			}
+
+			// An embedded pointer to a structure being decoded, such as
+			// *Node in Node, would be allocated endlessly.
+			if es.visiting[typeField.Type.Elem()] {
+				continue
+			}
+
+			if valueField.IsNil() {
+				valueField.Set(reflect.New(typeField.Type.Elem()))
+			}
+
+			err := d.decode(es, valueField.Elem(), path+typeField.Name+".", prefix+typeField.Tag.Get("envPrefix"))
 			if err != nil {
 				return err
 			}
+			continue
 		}
 
-		typeField := t.Field(i)
 		tag := typeField.Tag.Get("env")
-		if tag == "" {
+		if tag == "" || tag == "-" {
 			continue
 		}
 
@@ -91,89 +533,1174 @@ func unmarshal(es envSet, v interface{}) error {
 			return ErrUnexportedField
 		}
 
-		envTag := parseTag(tag)
+		envTag := d.parseTag(typeField, tag, prefix)
 
-		envValue, ok := es[envTag.Key]
-		if !ok {
-			if envTag.Default == "" {
-				continue
-			} else {
-				envValue = envTag.Default
+		if prefix := envTag.key(); strings.HasSuffix(prefix, "*") {
+			if typeField.Type != reflect.TypeOf(map[string]string(nil)) {
+				return ErrUnsupportedType
 			}
+			es.catchAll = append(es.catchAll, catchAll{
+				prefix: strings.TrimSuffix(prefix, "*"),
+				field:  valueField,
+			})
+			continue
 		}
 
-		err := set(typeField.Type, valueField, envValue)
-		if err != nil {
+		var start time.Time
+		if d.timing != nil {
+			start = time.Now()
+		}
+		key, err := d.decodeField(es, valueField, typeField.Type, envTag)
+		if d.timing != nil {
+			d.timing(path+typeField.Name, time.Since(start))
+		}
+		if d.skipUnsupported && errors.Is(err, ErrUnsupportedType) {
+			if d.onSkip != nil {
+				d.onSkip(path+typeField.Name, err)
+			}
+			continue
+		}
+		if err == ErrUnsupportedType {
 			return err
 		}
-
-		delete(es, tag)
+		if err != nil {
+			return d.fieldError(path+typeField.Name, key, err)
+		}
 	}
 
+	if initializer, ok := rv.Addr().Interface().(Initializer); ok {
+		es.initializers = append(es.initializers, initializer)
+	}
+	if v, ok := rv.Addr().Interface().(Validator); ok {
+		es.validators = append(es.validators, validator{path: strings.TrimSuffix(path, "."), validator: v})
+	}
 	return nil
 }
 
-func parseTag(tagString string) tag {
-	var t tag
-	envKeys := strings.Split(tagString, ",")
-	for _, key := range envKeys {
-		if strings.Contains(key, "=") {
-			keyData := strings.SplitN(key, "=", 2)
-			if strings.ToLower(keyData[0]) == "default" {
-				t.Default = keyData[1]
+// ownKeys records the fields of the structure type t and its nested
+// structures as the owners of the keys they look up, before decode sets
+// any of them. It returns an error wrapping ErrDuplicateKey for the first
+// key looked up by two fields.
+func (d *Decoder) ownKeys(es *envSet, t reflect.Type, path, prefix string) error {
+	es.visiting[t] = true
+	defer delete(es.visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+		switch typeField.Type.Kind() {
+		case reflect.Struct:
+			if typeField.PkgPath != "" {
+				continue
+			}
+
+			if !hasTaggedFields(typeField.Type) || d.isInline(typeField) {
+				break
+			}
+
+			err := d.ownKeys(es, typeField.Type, path+typeField.Name+".", prefix+typeField.Tag.Get("envPrefix"))
+			if err != nil {
+				return err
+			}
+		case reflect.Ptr:
+			if !isEmbeddedStructPtr(typeField) {
+				break
 			}
+
+			if es.visiting[typeField.Type.Elem()] {
+				continue
+			}
+
+			err := d.ownKeys(es, typeField.Type.Elem(), path+typeField.Name+".", prefix+typeField.Tag.Get("envPrefix"))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag := typeField.Tag.Get("env")
+		if tag == "" || tag == "-" || typeField.PkgPath != "" {
 			continue
 		}
-		t.Key = key
+
+		envTag := d.parseTag(typeField, tag, prefix)
+		keys := append(append([]string(nil), envTag.Keys...), envTag.Concat...)
+		if key, owner, ok := es.own(keys, path+typeField.Name); !ok {
+			return d.fieldError(path+typeField.Name, key, fmt.Errorf("%w: %s", ErrDuplicateKey, owner))
+		}
 	}
-	return t
+	return nil
 }
 
-func set(t reflect.Type, f reflect.Value, value string) error {
-	switch t.Kind() {
-	case reflect.Ptr:
-		ptr := reflect.New(t.Elem())
-		err := set(t.Elem(), ptr.Elem(), value)
-		if err != nil {
-			return err
+// decodeField looks up the value of the field f of type t and stores it
+// in f. It returns the key the value was read from, or the primary key if
+// the variables are missing.
+func (d *Decoder) decodeField(es *envSet, f reflect.Value, t reflect.Type, envTag tag) (string, error) {
+	key := envTag.key()
+	var envValue string
+	var ok bool
+	if len(envTag.Concat) > 0 {
+		envValue, ok = es.lookupConcat(envTag.Concat, envTag.Sep)
+	} else if foundKey, value, found := es.lookup(envTag.Keys); found {
+		key, envValue, ok = foundKey, value, true
+	}
+
+	readFile := envTag.File
+	if !ok && d.fileVariables {
+		if foundKey, value, found := es.lookup(transform(envTag.Keys, fileKey)); found {
+			key, envValue, ok, readFile = foundKey, value, true, true
 		}
-		f.Set(ptr)
-	case reflect.String:
-		f.SetString(value)
-	case reflect.Bool:
-		v, err := strconv.ParseBool(value)
-		if err != nil {
-			return err
+	}
+
+	if ok {
+		envValue = d.applyPolicy(es, envValue)
+	}
+
+	blank := ok && d.blankAsEmpty && strings.TrimSpace(envValue) == ""
+	if !ok || envValue == DefaultSentinel || blank {
+		if d.defaults != nil && !envTag.HasDefault && envTag.DefaultFunc == "" && !f.IsZero() {
+			// The baseline value set by WithDefaults is kept.
+			return key, nil
 		}
-		f.SetBool(v)
-	case reflect.Float32:
-		v, err := strconv.ParseFloat(value, 32)
+
+		value, found, err := d.missing(key, envTag)
 		if err != nil {
-			return err
+			return key, err
 		}
-		f.SetFloat(v)
-	case reflect.Float64:
-		v, err := strconv.ParseFloat(value, 64)
+		if !found {
+			// A blank value without a default is left out like a missing
+			// one, but it is still empty to notempty.
+			if blank && envTag.NotEmpty {
+				return key, envTag.message(ErrEmptyValue)
+			}
+			return key, nil
+		}
+		envValue = value
+		readFile = envTag.File
+	}
+
+	if readFile {
+		var err error
+		envValue, err = readSecret(envValue, envTag.KeepNewline)
 		if err != nil {
-			return err
+			return key, err
 		}
-		f.SetFloat(v)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if t.PkgPath() == "time" && t.Name() == "Duration" {
-			duration, err := time.ParseDuration(value)
+	}
+
+	if d.blankAsEmpty && strings.TrimSpace(envValue) == "" {
+		envValue = ""
+	}
+
+	if envTag.EchoTrue && indirect(t).Kind() == reflect.Bool && strings.EqualFold(envValue, key) {
+		envValue = "true"
+	}
+
+	if indirect(t).Kind() == reflect.String {
+		if envTag.Base64 {
+			decoded, err := base64.StdEncoding.DecodeString(envValue)
 			if err != nil {
-				return err
+				return key, err
 			}
-			f.Set(reflect.ValueOf(duration))
-			break
+			envValue = string(decoded)
+		}
+		if envTag.Lower {
+			envValue = strings.ToLower(envValue)
+		} else if envTag.Upper {
+			envValue = strings.ToUpper(envValue)
+		}
+	}
+
+	err := d.safeSet(t, f, envValue, envTag)
+	if err != nil {
+		return key, err
+	}
+
+	if envTag.Transform != "" {
+		fn, ok := lookupTransform(envTag.Transform)
+		if !ok {
+			return key, fmt.Errorf("%s: %w", envTag.Transform, ErrUnknownTransform)
 		}
-		v, err := strconv.Atoi(value)
+		err = fn(f)
 		if err != nil {
-			return err
+			return key, err
 		}
-		f.SetInt(int64(v))
-	default:
-		return ErrUnsupportedType
 	}
-	return nil
+
+	err = validate(f, envValue, envTag)
+	if err != nil {
+		return key, envTag.message(err)
+	}
+	return key, nil
+}
+
+// missing returns the value of a field whose variables are missing. It
+// reports false if the field has no value.
+func (d *Decoder) missing(key string, envTag tag) (string, bool, error) {
+	if envTag.HasDefault {
+		return envTag.Default, true, nil
+	}
+
+	if envTag.DefaultFunc != "" {
+		fn, ok := lookupDefaultFunc(envTag.DefaultFunc)
+		if !ok {
+			return "", false, fmt.Errorf("%s: %w", envTag.DefaultFunc, ErrUnknownDefaultFunc)
+		}
+		return fn(), true, nil
+	}
+
+	if d.onMissing != nil {
+		if value, ok := d.onMissing(key); ok {
+			return value, true, nil
+		}
+	}
+
+	if envTag.Required {
+		return "", false, envTag.message(ErrMissingRequired)
+	}
+	return "", false, nil
+}
+
+// own records the field at path as the owner of keys. If a key is owned
+// by another field, own returns it and its owner and reports false.
+func (es *envSet) own(keys []string, path string) (string, string, bool) {
+	if es.owners == nil {
+		es.owners = make(map[string]string)
+	}
+
+	for _, key := range keys {
+		if owner, ok := es.owners[key]; ok {
+			return key, owner, false
+		}
+	}
+	for _, key := range keys {
+		es.owners[key] = path
+	}
+	return "", "", true
+}
+
+// lookup returns the first of keys present in es and its value, and marks
+// it as used.
+func (es *envSet) lookup(keys []string) (string, string, bool) {
+	for _, key := range keys {
+		if value, ok := es.values[key]; ok {
+			es.used[key] = true
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+// lookupConcat joins the values of the keys present in es with sep and
+// marks them as used. It reports false if none of the keys are present.
+func (es *envSet) lookupConcat(keys []string, sep string) (string, bool) {
+	var parts []string
+	for _, key := range keys {
+		if value, ok := es.values[key]; ok {
+			es.used[key] = true
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, sep), len(parts) > 0
+}
+
+// fillCatchAll stores the unused variables in the catch-all fields and
+// marks them as used.
+func (es *envSet) fillCatchAll() {
+	for _, c := range es.catchAll {
+		m := make(map[string]string)
+		for _, key := range es.unused() {
+			if strings.HasPrefix(key, c.prefix) {
+				m[key] = es.values[key]
+				es.used[key] = true
+			}
+		}
+		c.field.Set(reflect.ValueOf(m))
+	}
+}
+
+// unused returns the sorted keys of es not used by any field.
+func (es *envSet) unused() []string {
+	var keys []string
+	for key := range es.values {
+		if !es.used[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parseTag(tagString string) tag {
+	return parseTagOptions(tagString, ",")
+}
+
+// parseTagOptions parses tagString whose keys and options are separated by
+// sep.
+func parseTagOptions(tagString, sep string) tag {
+	var t tag
+	var concat bool
+	envKeys := strings.Split(tagString, sep)
+	for i := 0; i < len(envKeys); i++ {
+		key := envKeys[i]
+		if strings.Contains(key, "=") {
+			keyData := strings.SplitN(key, "=", 2)
+			concat = false
+			switch strings.ToLower(keyData[0]) {
+			case "default":
+				// The default value takes the rest of the tag, so it may
+				// contain the separator.
+				t.Default = strings.Join(append([]string{keyData[1]}, envKeys[i+1:]...), sep)
+				t.HasDefault = true
+				return t
+			case "msg":
+				// Like the default value, the message takes the rest of
+				// the tag.
+				t.Msg = strings.Join(append([]string{keyData[1]}, envKeys[i+1:]...), sep)
+				return t
+			case "defaultfunc":
+				t.DefaultFunc = keyData[1]
+			case "transform":
+				t.Transform = keyData[1]
+			case "oneof":
+				t.OneOf = strings.Split(keyData[1], "|")
+			case "anyof":
+				t.AnyOf = strings.Split(keyData[1], "|")
+			case "schemes":
+				t.Schemes = strings.Split(keyData[1], "|")
+			case "min":
+				t.Min = keyData[1]
+			case "max":
+				t.Max = keyData[1]
+			case "maxlen":
+				t.MaxLen = keyData[1]
+			case "maxtotal":
+				t.MaxTotal = keyData[1]
+			case "base":
+				t.Base = keyData[1]
+			case "unit":
+				t.Unit = keyData[1]
+			case "layouts":
+				t.Layouts = strings.Split(keyData[1], "|")
+			case "layout":
+				t.Layouts = []string{keyData[1]}
+			case "tz":
+				t.TZ = keyData[1]
+			case "sep":
+				t.Sep = separatorOption(keyData[1], envKeys, &i, sep)
+			case "kvsep":
+				t.KVSep = separatorOption(keyData[1], envKeys, &i, sep)
+			case "concat":
+				t.Concat = append(t.Concat, keyData[1])
+				concat = true
+			}
+			continue
+		}
+		if i > 0 && parseFlag(&t, key) {
+			continue
+		}
+		if concat {
+			t.Concat = append(t.Concat, key)
+			continue
+		}
+		t.Keys = append(t.Keys, key)
+	}
+	return t
+}
+
+// separatorOption returns the value of a sep or kvsep option at index *i
+// of options. An empty value followed by an empty option is the tag
+// separator itself, as in `env:"A,sep=,,max=3"`, and the empty option is
+// skipped.
+func separatorOption(value string, options []string, i *int, sep string) string {
+	if value == "" && *i+1 < len(options) && options[*i+1] == "" {
+		*i++
+		return sep
+	}
+	return value
+}
+
+// message returns err with its text replaced by the msg option, if any.
+func (t tag) message(err error) error {
+	if t.Msg == "" {
+		return err
+	}
+	return &messageError{msg: t.Msg, err: err}
+}
+
+// messageError is an error with a custom text that still matches the
+// error it replaces with errors.Is.
+type messageError struct {
+	msg string
+	err error
+}
+
+func (e *messageError) Error() string {
+	return e.msg
+}
+
+func (e *messageError) Unwrap() error {
+	return e.err
+}
+
+// base returns the base of integer values.
+func (t tag) base() (int, error) {
+	if t.Hex {
+		return 16, nil
+	}
+	if t.Base == "" {
+		return 10, nil
+	}
+
+	base, err := strconv.Atoi(t.Base)
+	if err != nil || base == 1 || base < 0 || base > 36 {
+		return 0, fmt.Errorf("base: %w", ErrInvalidTag)
+	}
+	return base, nil
+}
+
+// split splits a slice or map value into its elements. With the trimempty
+// option, empty elements are dropped.
+func (t tag) split(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	parts := strings.Split(value, t.sep())
+	if !t.TrimEmpty {
+		return parts
+	}
+
+	nonEmpty := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return nonEmpty
+}
+
+// sep returns the separator of slice elements and map entries.
+func (t tag) sep() string {
+	if t.Sep == "" {
+		return ","
+	}
+	return t.Sep
+}
+
+// kvSep returns the separator of the key and value of a map entry.
+func (t tag) kvSep() string {
+	if t.KVSep == "" {
+		return "="
+	}
+	return t.KVSep
+}
+
+// key returns the primary key of the tag.
+func (t tag) key() string {
+	if len(t.Keys) == 0 {
+		return ""
+	}
+	return t.Keys[0]
+}
+
+// withPrefix returns t with prefix prepended to its keys.
+func (t tag) withPrefix(prefix string) tag {
+	if prefix == "" {
+		return t
+	}
+
+	add := func(key string) string { return prefix + key }
+	t.Keys = transform(t.Keys, add)
+	t.Concat = transform(t.Concat, add)
+	return t
+}
+
+// parseFlag sets the tag option named by flag and reports whether flag is
+// a known option rather than a key.
+func parseFlag(t *tag, flag string) bool {
+	switch flag {
+	case "required":
+		t.Required = true
+	case "defaultTrue":
+		t.Default = "true"
+		t.HasDefault = true
+	case "notempty":
+		t.NotEmpty = true
+	case "nonneg":
+		t.NonNeg = true
+	case "bool01":
+		t.Bool01 = true
+	case "trimempty":
+		t.TrimEmpty = true
+	case "append":
+		t.Append = true
+	case "file":
+		t.File = true
+	case "secret":
+		t.Secret = true
+	case "inline":
+		t.Inline = true
+	case "keepnewline":
+		t.KeepNewline = true
+	case "negate":
+		t.Negate = true
+	case "numericBool":
+		t.NumericBool = true
+	case "echoTrue":
+		t.EchoTrue = true
+	case "base64":
+		t.Base64 = true
+	case "lower":
+		t.Lower = true
+	case "upper":
+		t.Upper = true
+	case "percent":
+		t.Percent = true
+	case "si":
+		t.SI = true
+	case "hex":
+		t.Hex = true
+	case "secfloat":
+		t.SecFloat = true
+	case "clock":
+		t.Clock = true
+	default:
+		return false
+	}
+	return true
+}
+
+// isEmbeddedStructPtr reports whether field is an exported embedded
+// pointer to a structure with tagged fields, which is allocated if nil and
+// decoded like a nested structure.
+func isEmbeddedStructPtr(field reflect.StructField) bool {
+	if !field.Anonymous || field.PkgPath != "" || field.Type.Kind() != reflect.Ptr {
+		return false
+	}
+	if _, ok := field.Tag.Lookup("env"); ok {
+		return false
+	}
+	return field.Type.Elem().Kind() == reflect.Struct && hasTaggedFields(field.Type.Elem())
+}
+
+// isInline reports whether field is tagged with the inline option, so it
+// is decoded from a single value rather than as a nested structure.
+func isInline(field reflect.StructField) bool {
+	tagString, ok := field.Tag.Lookup("env")
+	return ok && parseTag(tagString).Inline
+}
+
+// indirect returns the type t points to, following any number of pointers.
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isEmptyStruct reports whether t is a structure without fields, such as
+// the element type of a set.
+func isEmptyStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
+
+// isDuration reports whether t is time.Duration.
+func isDuration(t reflect.Type) bool {
+	return t.PkgPath() == "time" && t.Name() == "Duration"
+}
+
+// timeType is the type of time.Time, decoded with the layouts option.
+var timeType = reflect.TypeOf(time.Time{})
+
+// atomicTypes maps the types of package sync/atomic, such as atomic.Int64,
+// to the types of the values they store. It is filled when the types are
+// available.
+var atomicTypes = make(map[reflect.Type]reflect.Type)
+
+// loadAtomic returns the value stored in f if its type is one of
+// atomicTypes, or f otherwise.
+func loadAtomic(f reflect.Value) reflect.Value {
+	if _, ok := atomicTypes[f.Type()]; ok && f.CanAddr() {
+		return f.Addr().MethodByName("Load").Call(nil)[0]
+	}
+	return f
+}
+
+// safeSet calls set and turns a panic of reflection on an unusual type
+// into an error wrapping ErrUnsupportedType.
+func (d *Decoder) safeSet(t reflect.Type, f reflect.Value, value string, envTag tag) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: %w: %v", t, ErrUnsupportedType, r)
+		}
+	}()
+	return d.set(t, f, value, envTag)
+}
+
+func (d *Decoder) set(t reflect.Type, f reflect.Value, value string, envTag tag) error {
+	if parser, ok := lookupParser(t); ok {
+		v, err := parser(value)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() || !rv.Type().AssignableTo(t) {
+			return ErrUnsupportedType
+		}
+		f.Set(rv)
+		return nil
+	}
+
+	if valueType, ok := atomicTypes[t]; ok {
+		v := reflect.New(valueType).Elem()
+		err := d.set(valueType, v, value, envTag)
+		if err != nil {
+			return err
+		}
+		f.Addr().MethodByName("Store").Call([]reflect.Value{v})
+		return nil
+	}
+
+	if envTag.Inline && t.Kind() == reflect.Struct {
+		return d.setInline(f, value, envTag)
+	}
+
+	if t == timeType && len(envTag.Layouts) > 0 {
+		v, err := parseTime(value, envTag)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		ptr := reflect.New(t.Elem())
+		err := d.set(t.Elem(), ptr.Elem(), value, envTag)
+		if err != nil {
+			return err
+		}
+		f.Set(ptr)
+	case reflect.Slice:
+		parts := envTag.split(value)
+		if envTag.MaxLen != "" {
+			maxLen, err := strconv.Atoi(envTag.MaxLen)
+			if err != nil || maxLen < 0 {
+				return fmt.Errorf("maxlen: %w", ErrInvalidTag)
+			}
+			if len(parts) > maxLen {
+				return fmt.Errorf("%w: %d elements, maxlen %d", ErrOutOfRange, len(parts), maxLen)
+			}
+		}
+		slice := reflect.MakeSlice(t, len(parts), len(parts))
+		for i, part := range parts {
+			err := d.set(t.Elem(), slice.Index(i), part, envTag)
+			if err == ErrUnsupportedType {
+				return err
+			}
+			if err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		if envTag.Append {
+			slice = reflect.AppendSlice(f, slice)
+		}
+		f.Set(slice)
+	case reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 || !envTag.Hex {
+			return ErrUnsupportedType
+		}
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return err
+		}
+		if len(decoded) != t.Len() {
+			return fmt.Errorf("%d bytes, want %d: %w", len(decoded), t.Len(), ErrInvalidLength)
+		}
+		reflect.Copy(f, reflect.ValueOf(decoded))
+	case reflect.Map:
+		if !isMapKey(t.Key()) {
+			return ErrUnsupportedType
+		}
+		parts := envTag.split(value)
+		m := reflect.MakeMapWithSize(t, len(parts))
+		for _, part := range parts {
+			key := reflect.New(t.Key()).Elem()
+			elem := reflect.New(t.Elem()).Elem()
+			if isEmptyStruct(t.Elem()) {
+				err := d.setMapKey(key, part)
+				if err != nil {
+					return err
+				}
+				m.SetMapIndex(key, elem)
+				continue
+			}
+
+			entry := strings.SplitN(part, envTag.kvSep(), 2)
+			if len(entry) != 2 {
+				return fmt.Errorf("%s: %w", part, ErrInvalidMapEntry)
+			}
+			err := d.setMapKey(key, entry[0])
+			if err != nil {
+				return err
+			}
+			err = d.set(t.Elem(), elem, entry[1], envTag)
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		f.Set(m)
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		v, err := parseBool(value, envTag)
+		if err != nil {
+			return err
+		}
+		f.SetBool(v != envTag.Negate)
+	case reflect.Float32:
+		if envTag.StrictTypes && !envTag.Percent && !isDecimalFloat(value) {
+			return fmt.Errorf("%w: %s", ErrNumberKind, value)
+		}
+		v, err := parseFloat(value, 32, envTag)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(v)
+	case reflect.Float64:
+		if envTag.StrictTypes && !envTag.Percent && !isDecimalFloat(value) {
+			return fmt.Errorf("%w: %s", ErrNumberKind, value)
+		}
+		v, err := parseFloat(value, 64, envTag)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isDuration(t) {
+			duration, err := parseDuration(value, envTag)
+			if err != nil {
+				return err
+			}
+			f.Set(reflect.ValueOf(duration))
+			break
+		}
+		if envTag.StrictTypes && isDecimalFloat(value) {
+			return fmt.Errorf("%w: %s", ErrNumberKind, value)
+		}
+		v, err := parseInt(value, t.Bits(), envTag)
+		if err != nil {
+			return err
+		}
+		f.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if envTag.StrictTypes && isDecimalFloat(value) {
+			return fmt.Errorf("%w: %s", ErrNumberKind, value)
+		}
+		v, err := parseUint(value, t.Bits(), envTag)
+		if err != nil {
+			return err
+		}
+		f.SetUint(v)
+	default:
+		return ErrUnsupportedType
+	}
+	return nil
+}
+
+// isMapKey reports whether maps with keys of type t are supported: keys
+// are strings, integers or bools.
+func isMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// setMapKey sets the map key f to value, parsed without the options of the
+// tag, which apply to the map values.
+func (d *Decoder) setMapKey(f reflect.Value, value string) error {
+	err := d.set(f.Type(), f, value, d.valueTag(tag{}))
+	if err != nil {
+		return fmt.Errorf("key %s: %w", value, err)
+	}
+	return nil
+}
+
+// isDecimalFloat reports whether value is a decimal float with a fraction
+// or an exponent, such as 1.0, .5 or 1e3, rather than an integer, a
+// hexadecimal float, an infinity or NaN.
+func isDecimalFloat(value string) bool {
+	if strings.Trim(value, "0123456789+-.eE") != "" || !strings.ContainsAny(value, ".eE") {
+		return false
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// parseFloat parses value as a float of the given bit size. With the
+// percent option, a value with a trailing "%" is divided by 100.
+func parseFloat(value string, bitSize int, envTag tag) (float64, error) {
+	if envTag.Percent && strings.HasSuffix(value, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), bitSize)
+		if err != nil {
+			return 0, err
+		}
+		return v / 100, nil
+	}
+	return strconv.ParseFloat(value, bitSize)
+}
+
+// fileKey returns the key of the variable naming the file that holds the
+// value of key.
+func fileKey(key string) string {
+	return key + "_FILE"
+}
+
+// readSecret returns the content of the file filename. A single trailing
+// newline is removed unless keepNewline is true.
+func readSecret(filename string, keepNewline bool) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	value := string(data)
+	if !keepNewline {
+		if strings.HasSuffix(value, "\r\n") {
+			value = strings.TrimSuffix(value, "\r\n")
+		} else {
+			value = strings.TrimSuffix(value, "\n")
+		}
+	}
+	return value, nil
+}
+
+// setInline stores the entries of value, such as "host=localhost;port=5432",
+// in the fields of the structure f tagged with their keys. Entries are
+// separated by ";" unless the sep option is set.
+func (d *Decoder) setInline(f reflect.Value, value string, envTag tag) error {
+	if envTag.Sep == "" {
+		envTag.Sep = ";"
+	}
+
+	if fields := positionalFields(f.Type()); fields != nil {
+		return d.setPositional(f, fields, envTag.split(value))
+	}
+
+	m := make(map[string]string)
+	for _, part := range envTag.split(value) {
+		entry := strings.SplitN(part, envTag.kvSep(), 2)
+		if len(entry) != 2 {
+			return fmt.Errorf("%s: %w", part, ErrInvalidMapEntry)
+		}
+		m[entry[0]] = entry[1]
+	}
+	return d.inlineDecoder().unmarshal(newEnvSet(m), f.Addr().Interface())
+}
+
+// inlineDecoder returns a Decoder for the fields of inline structures, with
+// the options of d that apply to tags and values but not those that apply
+// to keys, such as the prefix.
+func (d *Decoder) inlineDecoder() *Decoder {
+	return &Decoder{
+		tagOptionSep: d.tagOptionSep,
+		mapEntrySep:  d.mapEntrySep,
+		mapKeySep:    d.mapKeySep,
+		bools:        d.bools,
+		strictTypes:  d.strictTypes,
+	}
+}
+
+// positionalFields returns the indexes of the exported fields of the
+// structure t if none of its fields is tagged with "env", or nil otherwise.
+// Fields tagged `env:"-"` are left out. The inline values of such a
+// structure are assigned in field order.
+func positionalFields(t reflect.Type) []int {
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if tag, ok := field.Tag.Lookup("env"); ok {
+			if tag == "-" {
+				continue
+			}
+			return nil
+		}
+		if field.PkgPath == "" {
+			fields = append(fields, i)
+		}
+	}
+	return fields
+}
+
+// setPositional sets the fields of f at the indexes fields to parts in
+// order. There must be as many parts as fields.
+func (d *Decoder) setPositional(f reflect.Value, fields []int, parts []string) error {
+	if len(parts) != len(fields) {
+		return fmt.Errorf("%d values, want %d: %w", len(parts), len(fields), ErrInvalidLength)
+	}
+
+	for i, index := range fields {
+		field := f.Type().Field(index)
+		err := d.set(field.Type, f.Field(index), parts[i], d.valueTag(tag{}))
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseBool parses value as a bool. With the numericBool option the value
+// is an integer, true unless it is zero. Otherwise the values of the Bools
+// table of envTag match regardless of case.
+func parseBool(value string, envTag tag) (bool, error) {
+	if envTag.NumericBool {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return n != 0, nil
+	}
+	if v, ok := envTag.Bools[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// trimHexPrefix returns value without a "0x", "0X" or "#" prefix.
+func trimHexPrefix(value string) string {
+	for _, prefix := range []string{"0x", "0X", "#"} {
+		if strings.HasPrefix(value, prefix) {
+			return value[len(prefix):]
+		}
+	}
+	return value
+}
+
+// durationUnits maps the values of the unit option to durations.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// parseDuration parses value as a time.Duration. With the secfloat option
+// the value is a float number of seconds, with the clock option HH:MM:SS
+// or MM:SS, and with the unit option an integer number of the unit.
+func parseDuration(value string, envTag tag) (time.Duration, error) {
+	if envTag.Clock {
+		return parseClock(value)
+	}
+
+	if envTag.SecFloat {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, err
+		}
+		if math.IsNaN(seconds) || math.Abs(seconds) > float64(math.MaxInt64/time.Second) {
+			return 0, &strconv.NumError{Func: "ParseDuration", Num: value, Err: strconv.ErrRange}
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	if envTag.Unit != "" {
+		unit, ok := durationUnits[envTag.Unit]
+		if !ok {
+			return 0, fmt.Errorf("unit: %w", ErrInvalidTag)
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if n > math.MaxInt64/int64(unit) || n < -math.MaxInt64/int64(unit) {
+			return 0, &strconv.NumError{Func: "ParseDuration", Num: value, Err: strconv.ErrRange}
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// parseClock parses value as HH:MM:SS or MM:SS. Minutes and seconds must
+// be less than 60.
+func parseClock(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("parsing clock %q: must be HH:MM:SS or MM:SS", value)
+	}
+
+	var units [3]int64
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("parsing clock %q: minutes and seconds must be less than 60", value)
+		}
+		units[i] = int64(n)
+	}
+
+	seconds := units[0]*3600 + units[1]*60 + units[2]
+	if seconds > math.MaxInt64/int64(time.Second) {
+		return 0, &strconv.NumError{Func: "ParseDuration", Num: value, Err: strconv.ErrRange}
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// formatClock formats d as HH:MM:SS, truncated to seconds.
+func formatClock(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int64(d/time.Hour), int64(d/time.Minute%60), int64(d/time.Second%60))
+}
+
+// timeLayouts maps the names of the layouts option to the layouts of
+// package time.
+var timeLayouts = map[string]string{
+	"Layout":      time.Layout,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+// timeLayout returns the layout named name, or name itself.
+func timeLayout(name string) string {
+	if layout, ok := timeLayouts[name]; ok {
+		return layout
+	}
+	return name
+}
+
+// location returns the location of the tz option, UTC by default.
+func (t tag) location() (*time.Location, error) {
+	if t.TZ == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(t.TZ)
+	if err != nil {
+		return nil, fmt.Errorf("tz: %w", ErrInvalidTag)
+	}
+	return loc, nil
+}
+
+// parseTime parses value with the first of the layouts of envTag that
+// matches, in the location of its tz option.
+func parseTime(value string, envTag tag) (time.Time, error) {
+	loc, err := envTag.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range envTag.Layouts {
+		v, err := time.ParseInLocation(timeLayout(layout), value, loc)
+		if err == nil {
+			return v, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parsing time %q: no layout matches: %s", value, strings.Join(envTag.Layouts, ", "))
+}
+
+// siMultipliers maps the suffixes of the si option to their multipliers.
+var siMultipliers = map[string]uint64{
+	"K": 1e3,
+	"k": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+}
+
+// splitSI splits an SI suffix from value and returns the number and the
+// multiplier of the suffix, 1 if there is none.
+func splitSI(value string) (string, uint64) {
+	if value == "" {
+		return value, 1
+	}
+	if multiplier, ok := siMultipliers[value[len(value)-1:]]; ok {
+		return value[:len(value)-1], multiplier
+	}
+	return value, 1
+}
+
+// parseInt parses value as an integer of the given bit size in the base
+// of the base option. With the si option, a K, M or G suffix multiplies
+// the number.
+func parseInt(value string, bitSize int, envTag tag) (int64, error) {
+	multiplier := uint64(1)
+	number := value
+	if envTag.SI {
+		number, multiplier = splitSI(value)
+	}
+
+	base, err := envTag.base()
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseInt(number, base, bitSize)
+	if err != nil || multiplier == 1 {
+		return v, err
+	}
+
+	m := int64(multiplier)
+	limit := int64(1)<<(bitSize-1) - 1
+	if v > limit/m || v < -limit/m {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: value, Err: strconv.ErrRange}
+	}
+	return v * m, nil
+}
+
+// parseUint parses value as an unsigned integer of the given bit size in
+// the base of the base option. With the si option, a K, M or G suffix
+// multiplies the number.
+func parseUint(value string, bitSize int, envTag tag) (uint64, error) {
+	multiplier := uint64(1)
+	number := value
+	if envTag.SI {
+		number, multiplier = splitSI(value)
+	}
+
+	base, err := envTag.base()
+	if err != nil {
+		return 0, err
+	}
+
+	if envTag.Hex {
+		number = trimHexPrefix(number)
+	}
+
+	v, err := strconv.ParseUint(number, base, bitSize)
+	if err != nil || multiplier == 1 {
+		return v, err
+	}
+
+	limit := uint64(1)<<bitSize - 1
+	if v > limit/multiplier {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: value, Err: strconv.ErrRange}
+	}
+	return v * multiplier, nil
 }
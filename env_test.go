@@ -1,9 +1,15 @@
 package env_test
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/serge64/env"
 )
@@ -65,6 +71,65 @@ type DefaultValueStruct struct {
 	DefaultWithOptionsPresent string        `env:"MISSING_1,PRESENT,default=present"`
 }
 
+type SecFloatStruct struct {
+	Timeout time.Duration `env:"SECFLOAT_TIMEOUT,secfloat"`
+}
+
+type RequiredStruct struct {
+	Required string `env:"REQUIRED_MISSING,required"`
+}
+
+type ConcatStruct struct {
+	Token string `env:"TOKEN,concat=CONCAT_PART1,CONCAT_PART2,CONCAT_PART3,sep=."`
+}
+
+type CaseStruct struct {
+	Lower        string  `env:"CASE_REGION,lower"`
+	Upper        string  `env:"CASE_REGION,upper"`
+	PointerLower *string `env:"CASE_REGION,lower"`
+}
+
+type Base64Struct struct {
+	Token string `env:"BASE64_TOKEN,base64"`
+}
+
+type DataStruct struct {
+	Data string `env:"DATA"`
+}
+
+type UntaggedNestedStruct struct {
+	Name string `env:"UNTAGGED_NAME"`
+
+	// Created has no tagged fields and should not be recursed into.
+	Created time.Time
+
+	// Metadata has no tagged fields and should remain untouched.
+	Metadata struct {
+		Name  string
+		Count int
+	}
+}
+
+type PercentStruct struct {
+	CPULimit float64 `env:"PERCENT_CPU_LIMIT,percent"`
+	Ratio    float32 `env:"PERCENT_RATIO,percent"`
+}
+
+type SliceStruct struct {
+	Hosts       []string        `env:"SLICE_HOSTS"`
+	Ports       []int           `env:"SLICE_PORTS,sep=;"`
+	Timeouts    []time.Duration `env:"SLICE_TIMEOUTS"`
+	Tags        []string        `env:"SLICE_TAGS,sep=;,default=a;b;c"`
+	DefaultList []string        `env:"SLICE_DEFAULT_LIST,default=a,b"`
+}
+
+type SIStruct struct {
+	Rate  int    `env:"SI_RATE,si"`
+	Limit uint32 `env:"SI_LIMIT,si"`
+	Plain int    `env:"SI_PLAIN,si"`
+	Small int8   `env:"SI_SMALL,si"`
+}
+
 func TestUnmarshal(t *testing.T) {
 	environ := map[string]string{
 		"HOME":             "/home/test",
@@ -234,3 +299,1599 @@ func TestUnmarshalDefaultValues(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalSecFloat(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"1.5", 1500 * time.Millisecond},
+		{"0.25", 250 * time.Millisecond},
+	}
+
+	for _, testCase := range testCases {
+		_ = os.Setenv("SECFLOAT_TIMEOUT", testCase.value)
+
+		var secFloatStruct SecFloatStruct
+		err := env.Unmarshal(&secFloatStruct)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if secFloatStruct.Timeout != testCase.expected {
+			t.Errorf("Expected field value to be '%s' but got '%s'", testCase.expected, secFloatStruct.Timeout)
+		}
+	}
+
+	for _, value := range []string{"NaN", "Inf", "-Inf", "1e300", "9300000000"} {
+		var secFloatStruct SecFloatStruct
+		err := env.UnmarshalMap(map[string]string{"SECFLOAT_TIMEOUT": value}, &secFloatStruct)
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("Expected error 'ErrRange' for '%s' but got '%v'", value, err)
+		}
+	}
+}
+
+func TestUnmarshalRequired(t *testing.T) {
+	var requiredStruct RequiredStruct
+	err := env.Unmarshal(&requiredStruct)
+	if !errors.Is(err, env.ErrMissingRequired) {
+		t.Errorf("Expected error 'ErrMissingRequired' but got '%v'", err)
+	}
+
+	_ = os.Setenv("REQUIRED_MISSING", "present")
+	defer os.Unsetenv("REQUIRED_MISSING")
+
+	err = env.Unmarshal(&requiredStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if requiredStruct.Required != "present" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "present", requiredStruct.Required)
+	}
+}
+
+func TestUnmarshalConcat(t *testing.T) {
+	_ = os.Setenv("CONCAT_PART1", "header")
+	_ = os.Setenv("CONCAT_PART3", "signature")
+
+	var concatStruct ConcatStruct
+	err := env.Unmarshal(&concatStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if concatStruct.Token != "header.signature" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "header.signature", concatStruct.Token)
+	}
+}
+
+func TestUnmarshalCase(t *testing.T) {
+	_ = os.Setenv("CASE_REGION", "Us-East-1")
+
+	var caseStruct CaseStruct
+	err := env.Unmarshal(&caseStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if caseStruct.Lower != "us-east-1" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "us-east-1", caseStruct.Lower)
+	}
+
+	if caseStruct.Upper != "US-EAST-1" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "US-EAST-1", caseStruct.Upper)
+	}
+
+	if caseStruct.PointerLower == nil {
+		t.Errorf("Expected field value to be '%s' but got '%v'", "us-east-1", nil)
+	} else if *caseStruct.PointerLower != "us-east-1" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "us-east-1", *caseStruct.PointerLower)
+	}
+}
+
+func TestUnmarshalBase64(t *testing.T) {
+	_ = os.Setenv("BASE64_TOKEN", "c2VjcmV0IHRva2Vu")
+
+	var base64Struct Base64Struct
+	err := env.Unmarshal(&base64Struct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if base64Struct.Token != "secret token" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "secret token", base64Struct.Token)
+	}
+
+	_ = os.Setenv("BASE64_TOKEN", "not base64!")
+
+	err = env.Unmarshal(&base64Struct)
+	if err == nil || !strings.HasPrefix(err.Error(), "BASE64_TOKEN: ") {
+		t.Errorf("Expected error wrapped with key 'BASE64_TOKEN' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalEnviron(t *testing.T) {
+	environ := []string{
+		"HOME=/home/environ",
+		"WORKSPACE=/workspace",
+		"INT=42",
+		"MALFORMED",
+	}
+
+	var validStruct ValidStruct
+	err := env.UnmarshalEnviron(environ, &validStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if validStruct.Home != "/home/environ" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "/home/environ", validStruct.Home)
+	}
+
+	if validStruct.Jenkins.Workspace != "/workspace" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "/workspace", validStruct.Jenkins.Workspace)
+	}
+
+	if validStruct.Int != 42 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 42, validStruct.Int)
+	}
+}
+
+func TestUnmarshalValueWithEquals(t *testing.T) {
+	_ = os.Setenv("DATA", "a=b=c")
+
+	var dataStruct DataStruct
+	err := env.Unmarshal(&dataStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if dataStruct.Data != "a=b=c" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "a=b=c", dataStruct.Data)
+	}
+}
+
+func TestUnmarshalUntaggedNested(t *testing.T) {
+	_ = os.Setenv("UNTAGGED_NAME", "name")
+
+	var untaggedNestedStruct UntaggedNestedStruct
+	untaggedNestedStruct.Metadata.Name = "metadata"
+	err := env.Unmarshal(&untaggedNestedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if untaggedNestedStruct.Name != "name" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "name", untaggedNestedStruct.Name)
+	}
+
+	if !untaggedNestedStruct.Created.IsZero() {
+		t.Errorf("Expected zero time but got '%s'", untaggedNestedStruct.Created)
+	}
+
+	if untaggedNestedStruct.Metadata.Name != "metadata" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "metadata", untaggedNestedStruct.Metadata.Name)
+	}
+}
+
+func TestUnmarshalPercent(t *testing.T) {
+	_ = os.Setenv("PERCENT_CPU_LIMIT", "75%")
+	_ = os.Setenv("PERCENT_RATIO", "0.5")
+
+	var percentStruct PercentStruct
+	err := env.Unmarshal(&percentStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if percentStruct.CPULimit != 0.75 {
+		t.Errorf("Expected field value to be '%f' but got '%f'", 0.75, percentStruct.CPULimit)
+	}
+
+	if percentStruct.Ratio != 0.5 {
+		t.Errorf("Expected field value to be '%f' but got '%f'", 0.5, percentStruct.Ratio)
+	}
+}
+
+func TestUnmarshalSlice(t *testing.T) {
+	_ = os.Setenv("SLICE_HOSTS", "a.example.com,b.example.com")
+	_ = os.Setenv("SLICE_PORTS", "80;443")
+	_ = os.Setenv("SLICE_TIMEOUTS", "1s,2m")
+
+	var sliceStruct SliceStruct
+	err := env.Unmarshal(&sliceStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	testCases := [][]interface{}{
+		{sliceStruct.Hosts, []string{"a.example.com", "b.example.com"}},
+		{sliceStruct.Ports, []int{80, 443}},
+		{sliceStruct.Timeouts, []time.Duration{time.Second, 2 * time.Minute}},
+		{sliceStruct.Tags, []string{"a", "b", "c"}},
+		{sliceStruct.DefaultList, []string{"a", "b"}},
+	}
+
+	for _, testCase := range testCases {
+		if !reflect.DeepEqual(testCase[0], testCase[1]) {
+			t.Errorf("Expected field value to be '%v' but got '%v'", testCase[1], testCase[0])
+		}
+	}
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	m := map[string]string{
+		"HOME": "/home/map",
+		"INT":  "3",
+	}
+
+	var validStruct ValidStruct
+	err := env.UnmarshalMap(m, &validStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if validStruct.Home != "/home/map" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "/home/map", validStruct.Home)
+	}
+
+	if validStruct.Int != 3 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 3, validStruct.Int)
+	}
+}
+
+func TestUnmarshalStrictFromMap(t *testing.T) {
+	m := map[string]string{
+		"CONCAT_PART1": "header",
+		"CONCAT_PART2": "payload",
+	}
+
+	var concatStruct ConcatStruct
+	err := env.UnmarshalStrictFromMap(m, &concatStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	m["EXTRA_B"] = "b"
+	m["EXTRA_A"] = "a"
+
+	err = env.UnmarshalStrictFromMap(m, &concatStruct)
+	if !errors.Is(err, env.ErrUnknownKey) {
+		t.Errorf("Expected error 'ErrUnknownKey' but got '%v'", err)
+	}
+
+	if err != nil && !strings.HasPrefix(err.Error(), "EXTRA_A, EXTRA_B: ") {
+		t.Errorf("Expected error to list unknown keys but got '%s'", err)
+	}
+}
+
+func TestUnmarshalStrictFromMapCatchAll(t *testing.T) {
+	m := map[string]string{
+		"STRICT_HOST":    "localhost",
+		"STRICT_EXTRA_A": "a",
+		"STRICT_EXTRA_B": "b",
+	}
+
+	var prefixedStruct struct {
+		Host  string            `env:"STRICT_HOST"`
+		Extra map[string]string `env:"STRICT_EXTRA_*"`
+	}
+	err := env.UnmarshalStrictFromMap(m, &prefixedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := map[string]string{"STRICT_EXTRA_A": "a", "STRICT_EXTRA_B": "b"}
+	if !reflect.DeepEqual(prefixedStruct.Extra, expected) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expected, prefixedStruct.Extra)
+	}
+
+	m["OTHER"] = "other"
+	err = env.UnmarshalStrictFromMap(m, &prefixedStruct)
+	if err == nil || err.Error() != "OTHER: "+env.ErrUnknownKey.Error() {
+		t.Errorf("Expected error 'OTHER: %s' but got '%v'", env.ErrUnknownKey, err)
+	}
+
+	var catchAllStruct struct {
+		Host string            `env:"STRICT_HOST"`
+		Rest map[string]string `env:"*"`
+	}
+	err = env.UnmarshalStrictFromMap(m, &catchAllStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if len(catchAllStruct.Rest) != 3 {
+		t.Errorf("Expected '%d' variables but got '%d'", 3, len(catchAllStruct.Rest))
+	}
+}
+
+func TestUnmarshalSI(t *testing.T) {
+	_ = os.Setenv("SI_RATE", "5K")
+	_ = os.Setenv("SI_LIMIT", "2M")
+	_ = os.Setenv("SI_PLAIN", "42")
+	_ = os.Setenv("SI_SMALL", "1")
+
+	var siStruct SIStruct
+	err := env.Unmarshal(&siStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if siStruct.Rate != 5000 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 5000, siStruct.Rate)
+	}
+
+	if siStruct.Limit != 2000000 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 2000000, siStruct.Limit)
+	}
+
+	if siStruct.Plain != 42 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 42, siStruct.Plain)
+	}
+
+	_ = os.Setenv("SI_SMALL", "1K")
+
+	err = env.Unmarshal(&siStruct)
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Expected error 'ErrRange' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalUint(t *testing.T) {
+	_ = os.Setenv("UINT_VALUE", "300")
+	defer os.Unsetenv("UINT_VALUE")
+
+	var uintStruct struct {
+		Uint   uint   `env:"UINT_VALUE"`
+		Uint16 uint16 `env:"UINT_VALUE"`
+	}
+	err := env.Unmarshal(&uintStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if uintStruct.Uint != 300 || uintStruct.Uint16 != 300 {
+		t.Errorf("Expected field values to be '%d' but got '%d' and '%d'", 300, uintStruct.Uint, uintStruct.Uint16)
+	}
+
+	var uint8Struct struct {
+		Uint8 uint8 `env:"UINT_VALUE"`
+	}
+	err = env.Unmarshal(&uint8Struct)
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Expected error 'ErrRange' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalNotEmpty(t *testing.T) {
+	_ = os.Setenv("NOT_EMPTY_VALUE", "")
+	defer os.Unsetenv("NOT_EMPTY_VALUE")
+
+	var notEmptyStruct struct {
+		Value string `env:"NOT_EMPTY_VALUE,notempty"`
+	}
+	err := env.Unmarshal(&notEmptyStruct)
+	if !errors.Is(err, env.ErrEmptyValue) {
+		t.Errorf("Expected error 'ErrEmptyValue' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalCatchAll(t *testing.T) {
+	m := map[string]string{
+		"CATCH_NAME":  "name",
+		"CATCH_EXTRA": "extra",
+		"CATCH_OTHER": "other",
+		"UNRELATED":   "unrelated",
+	}
+
+	var catchAllStruct struct {
+		Name string            `env:"CATCH_NAME"`
+		Rest map[string]string `env:"CATCH_*"`
+		All  map[string]string `env:"*"`
+	}
+	err := env.UnmarshalMap(m, &catchAllStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if catchAllStruct.Name != "name" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "name", catchAllStruct.Name)
+	}
+
+	expectedRest := map[string]string{
+		"CATCH_EXTRA": "extra",
+		"CATCH_OTHER": "other",
+	}
+	if !reflect.DeepEqual(catchAllStruct.Rest, expectedRest) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedRest, catchAllStruct.Rest)
+	}
+
+	expectedAll := map[string]string{
+		"UNRELATED": "unrelated",
+	}
+	if !reflect.DeepEqual(catchAllStruct.All, expectedAll) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedAll, catchAllStruct.All)
+	}
+}
+
+func TestUnmarshalMapField(t *testing.T) {
+	m := map[string]string{
+		"MAP_LABELS":  "team=core,tier=1",
+		"MAP_LIMITS":  "cpu:2;memory:4",
+		"MAP_FLAGS":   "a,b,c",
+		"MAP_INVALID": "a",
+	}
+
+	var mapStruct struct {
+		Labels map[string]string   `env:"MAP_LABELS"`
+		Limits map[string]int      `env:"MAP_LIMITS,sep=;,kvsep=:"`
+		Flags  map[string]struct{} `env:"MAP_FLAGS"`
+	}
+	err := env.UnmarshalMap(m, &mapStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedLabels := map[string]string{"team": "core", "tier": "1"}
+	if !reflect.DeepEqual(mapStruct.Labels, expectedLabels) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedLabels, mapStruct.Labels)
+	}
+
+	expectedLimits := map[string]int{"cpu": 2, "memory": 4}
+	if !reflect.DeepEqual(mapStruct.Limits, expectedLimits) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedLimits, mapStruct.Limits)
+	}
+
+	if len(mapStruct.Flags) != 3 {
+		t.Errorf("Expected '%d' flags but got '%d'", 3, len(mapStruct.Flags))
+	}
+
+	for _, flag := range []string{"a", "b", "c"} {
+		if _, ok := mapStruct.Flags[flag]; !ok {
+			t.Errorf("Expected flag '%s' to be set", flag)
+		}
+	}
+
+	if _, ok := mapStruct.Flags["d"]; ok {
+		t.Errorf("Expected flag '%s' not to be set", "d")
+	}
+
+	var invalidStruct struct {
+		Invalid map[string]string `env:"MAP_INVALID"`
+	}
+	err = env.UnmarshalMap(m, &invalidStruct)
+	if !errors.Is(err, env.ErrInvalidMapEntry) {
+		t.Errorf("Expected error 'ErrInvalidMapEntry' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalMapTypedKeys(t *testing.T) {
+	m := map[string]string{
+		"TYPED_NAMES":   "0=a,1=b,10=c",
+		"TYPED_PORTS":   "80,443",
+		"TYPED_INVALID": "x=a",
+		"TYPED_FLOATS":  "0.5=a",
+	}
+
+	var typedStruct struct {
+		Names map[int]string      `env:"TYPED_NAMES"`
+		Ports map[uint16]struct{} `env:"TYPED_PORTS"`
+	}
+	err := env.UnmarshalMap(m, &typedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedNames := map[int]string{0: "a", 1: "b", 10: "c"}
+	if !reflect.DeepEqual(typedStruct.Names, expectedNames) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedNames, typedStruct.Names)
+	}
+
+	expectedPorts := map[uint16]struct{}{80: {}, 443: {}}
+	if !reflect.DeepEqual(typedStruct.Ports, expectedPorts) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedPorts, typedStruct.Ports)
+	}
+
+	data, err := env.Marshal(&typedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := "TYPED_NAMES=0=a,1=b,10=c\nTYPED_PORTS=80,443\n"
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+
+	var invalidStruct struct {
+		Names map[int]string `env:"TYPED_INVALID"`
+	}
+	err = env.UnmarshalMap(m, &invalidStruct)
+
+	expectedErr := `TYPED_INVALID: key x: strconv.ParseInt: parsing "x": invalid syntax`
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected error '%s' but got '%v'", expectedErr, err)
+	}
+
+	var floatStruct struct {
+		Floats map[float64]string `env:"TYPED_FLOATS"`
+	}
+	err = env.UnmarshalMap(m, &floatStruct)
+	if !errors.Is(err, env.ErrUnsupportedType) {
+		t.Errorf("Expected error to be '%s' but got '%v'", env.ErrUnsupportedType, err)
+	}
+}
+
+func TestUnmarshalMessage(t *testing.T) {
+	var messageStruct struct {
+		DatabaseURL string `env:"MESSAGE_DB_URL,required,msg=database URL is mandatory, see the docs"`
+	}
+	err := env.UnmarshalMap(map[string]string{}, &messageStruct)
+	if !errors.Is(err, env.ErrMissingRequired) {
+		t.Errorf("Expected error 'ErrMissingRequired' but got '%v'", err)
+	}
+
+	expected := "MESSAGE_DB_URL: database URL is mandatory, see the docs"
+	if err != nil && err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, err)
+	}
+
+	var rangeStruct struct {
+		Workers int `env:"MESSAGE_WORKERS,max=4,msg=at most 4 workers"`
+	}
+	err = env.UnmarshalMap(map[string]string{"MESSAGE_WORKERS": "5"}, &rangeStruct)
+	if !errors.Is(err, env.ErrOutOfRange) {
+		t.Errorf("Expected error 'ErrOutOfRange' but got '%v'", err)
+	}
+
+	expected = "MESSAGE_WORKERS: at most 4 workers"
+	if err != nil && err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, err)
+	}
+}
+
+func TestUnmarshalSliceTrimEmpty(t *testing.T) {
+	m := map[string]string{"TRIM_EMPTY_HOSTS": ",a,,b,"}
+
+	var trimEmptyStruct struct {
+		Trimmed   []string `env:"TRIM_EMPTY_HOSTS,trimempty"`
+		Preserved []string `env:"TRIM_EMPTY_HOSTS"`
+	}
+	err := env.UnmarshalMap(m, &trimEmptyStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedTrimmed := []string{"a", "b"}
+	if !reflect.DeepEqual(trimEmptyStruct.Trimmed, expectedTrimmed) {
+		t.Errorf("Expected field value to be '%q' but got '%q'", expectedTrimmed, trimEmptyStruct.Trimmed)
+	}
+
+	expectedPreserved := []string{"", "a", "", "b", ""}
+	if !reflect.DeepEqual(trimEmptyStruct.Preserved, expectedPreserved) {
+		t.Errorf("Expected field value to be '%q' but got '%q'", expectedPreserved, trimEmptyStruct.Preserved)
+	}
+}
+
+func TestUnmarshalSliceAppend(t *testing.T) {
+	m := map[string]string{"APPEND_HOSTS": "c,d"}
+
+	appendStruct := struct {
+		Appended []string `env:"APPEND_HOSTS,append"`
+		Replaced []string `env:"APPEND_HOSTS"`
+	}{
+		Appended: []string{"a", "b"},
+		Replaced: []string{"a", "b"},
+	}
+	err := env.UnmarshalMap(m, &appendStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedAppended := []string{"a", "b", "c", "d"}
+	if !reflect.DeepEqual(appendStruct.Appended, expectedAppended) {
+		t.Errorf("Expected field value to be '%q' but got '%q'", expectedAppended, appendStruct.Appended)
+	}
+
+	expectedReplaced := []string{"c", "d"}
+	if !reflect.DeepEqual(appendStruct.Replaced, expectedReplaced) {
+		t.Errorf("Expected field value to be '%q' but got '%q'", expectedReplaced, appendStruct.Replaced)
+	}
+}
+
+func TestUnmarshalNegate(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"true", false},
+		{"false", true},
+	}
+
+	for _, test := range tests {
+		m := map[string]string{"DISABLE_CACHE": test.value}
+
+		var negateStruct struct {
+			CacheEnabled bool `env:"DISABLE_CACHE,negate"`
+		}
+		err := env.UnmarshalMap(m, &negateStruct)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if negateStruct.CacheEnabled != test.expected {
+			t.Errorf("Expected field value to be '%t' but got '%t'", test.expected, negateStruct.CacheEnabled)
+		}
+	}
+}
+
+func TestUnmarshalTimeLayouts(t *testing.T) {
+	m := map[string]string{
+		"LAYOUTS_DATE":    "2016/07/15",
+		"LAYOUTS_RFC3339": "2016-07-15T12:00:00Z",
+		"LAYOUTS_INVALID": "15.07.2016",
+	}
+
+	var layoutsStruct struct {
+		Date    time.Time  `env:"LAYOUTS_DATE,layouts=2006-01-02|2006/01/02|RFC3339"`
+		RFC3339 *time.Time `env:"LAYOUTS_RFC3339,layouts=2006-01-02|RFC3339"`
+	}
+	err := env.UnmarshalMap(m, &layoutsStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := time.Date(2016, 7, 15, 0, 0, 0, 0, time.UTC)
+	if !layoutsStruct.Date.Equal(expected) {
+		t.Errorf("Expected field value to be '%s' but got '%s'", expected, layoutsStruct.Date)
+	}
+
+	expected = time.Date(2016, 7, 15, 12, 0, 0, 0, time.UTC)
+	if layoutsStruct.RFC3339 == nil || !layoutsStruct.RFC3339.Equal(expected) {
+		t.Errorf("Expected field value to be '%s' but got '%v'", expected, layoutsStruct.RFC3339)
+	}
+
+	var invalidStruct struct {
+		Date time.Time `env:"LAYOUTS_INVALID,layouts=2006-01-02|RFC3339"`
+	}
+	err = env.UnmarshalMap(m, &invalidStruct)
+
+	expectedErr := `LAYOUTS_INVALID: parsing time "15.07.2016": no layout matches: 2006-01-02, RFC3339`
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected error '%s' but got '%v'", expectedErr, err)
+	}
+}
+
+func TestUnmarshalNumericBool(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"2", true},
+		{"-1", true},
+		{"0", false},
+	}
+
+	for _, test := range tests {
+		m := map[string]string{"NUMERIC_BOOL": test.value}
+
+		var numericBoolStruct struct {
+			Flag bool `env:"NUMERIC_BOOL,numericBool"`
+		}
+		err := env.UnmarshalMap(m, &numericBoolStruct)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if numericBoolStruct.Flag != test.expected {
+			t.Errorf("Expected field value to be '%t' but got '%t'", test.expected, numericBoolStruct.Flag)
+		}
+	}
+
+	m := map[string]string{"NUMERIC_BOOL": "true"}
+
+	var numericBoolStruct struct {
+		Flag bool `env:"NUMERIC_BOOL,numericBool"`
+	}
+	err := env.UnmarshalMap(m, &numericBoolStruct)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected error 'ErrSyntax' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalDefaultSentinel(t *testing.T) {
+	m := map[string]string{
+		"SENTINEL_HOST":     env.DefaultSentinel,
+		"SENTINEL_PORT":     env.DefaultSentinel,
+		"SENTINEL_REQUIRED": env.DefaultSentinel,
+	}
+
+	var sentinelStruct struct {
+		Host string `env:"SENTINEL_HOST,default=localhost"`
+		Port int    `env:"SENTINEL_PORT,default=8080"`
+	}
+	err := env.UnmarshalMap(m, &sentinelStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if sentinelStruct.Host != "localhost" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "localhost", sentinelStruct.Host)
+	}
+
+	if sentinelStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 8080, sentinelStruct.Port)
+	}
+
+	var requiredStruct struct {
+		Required string `env:"SENTINEL_REQUIRED,required"`
+	}
+	err = env.UnmarshalMap(m, &requiredStruct)
+	if !errors.Is(err, env.ErrMissingRequired) {
+		t.Errorf("Expected error 'ErrMissingRequired' but got '%v'", err)
+	}
+}
+
+type EmbeddedBase struct {
+	Name string `env:"NAME"`
+	Port int    `env:"PORT,default=8080"`
+}
+
+type EmbeddedPointerStruct struct {
+	*EmbeddedBase `envPrefix:"EMBEDDED_"`
+
+	Database struct {
+		Host string `env:"HOST"`
+	} `envPrefix:"EMBEDDED_DB_"`
+}
+
+type SelfEmbeddedStruct struct {
+	*SelfEmbeddedStruct
+
+	X string `env:"SELF_X"`
+}
+
+func TestUnmarshalSelfEmbeddedPointer(t *testing.T) {
+	var selfStruct SelfEmbeddedStruct
+	err := env.UnmarshalMap(map[string]string{"SELF_X": "x"}, &selfStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if selfStruct.X != "x" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "x", selfStruct.X)
+	}
+
+	if selfStruct.SelfEmbeddedStruct != nil {
+		t.Errorf("Expected embedded pointer to be nil but got '%v'", selfStruct.SelfEmbeddedStruct)
+	}
+}
+
+func TestUnmarshalEmbeddedPointer(t *testing.T) {
+	m := map[string]string{
+		"EMBEDDED_NAME":    "service",
+		"EMBEDDED_DB_HOST": "db.local",
+		"NAME":             "unprefixed",
+	}
+
+	var embeddedStruct EmbeddedPointerStruct
+	err := env.UnmarshalMap(m, &embeddedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if embeddedStruct.EmbeddedBase == nil {
+		t.Fatalf("Expected embedded pointer to be allocated")
+	}
+
+	if embeddedStruct.Name != "service" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "service", embeddedStruct.Name)
+	}
+
+	if embeddedStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 8080, embeddedStruct.Port)
+	}
+
+	if embeddedStruct.Database.Host != "db.local" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "db.local", embeddedStruct.Database.Host)
+	}
+
+	err = env.UnmarshalMap(map[string]string{"EMBEDDED_PORT": "port"}, &embeddedStruct)
+
+	expected := `EMBEDDED_PORT: strconv.ParseInt: parsing "port": invalid syntax`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%v'", expected, err)
+	}
+}
+
+func TestUnmarshalTriStateBool(t *testing.T) {
+	type triStateStruct struct {
+		Flag *bool `env:"TRI_STATE_FLAG"`
+	}
+
+	var unsetStruct triStateStruct
+	err := env.UnmarshalMap(map[string]string{}, &unsetStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if unsetStruct.Flag != nil {
+		t.Errorf("Expected field value to be nil but got '%t'", *unsetStruct.Flag)
+	}
+
+	for _, expected := range []bool{true, false} {
+		m := map[string]string{"TRI_STATE_FLAG": strconv.FormatBool(expected)}
+
+		var setStruct triStateStruct
+		err = env.UnmarshalMap(m, &setStruct)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if setStruct.Flag == nil || *setStruct.Flag != expected {
+			t.Errorf("Expected field value to be '%t' but got '%v'", expected, setStruct.Flag)
+		}
+	}
+
+	var emptyStruct triStateStruct
+	err = env.UnmarshalMap(map[string]string{"TRI_STATE_FLAG": ""}, &emptyStruct)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected error 'ErrSyntax' but got '%v'", err)
+	}
+
+	if emptyStruct.Flag != nil {
+		t.Errorf("Expected field value to be nil but got '%t'", *emptyStruct.Flag)
+	}
+}
+
+func TestUnmarshalFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "secret")
+	err := os.WriteFile(filename, []byte("s3cr3t\r\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := map[string]string{"FILE_SECRET": filename}
+
+	var fileStruct struct {
+		Trimmed   string `env:"FILE_SECRET,file"`
+		Preserved string `env:"FILE_SECRET,file,keepnewline"`
+		Path      string `env:"FILE_SECRET"`
+	}
+	err = env.UnmarshalMap(m, &fileStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if fileStruct.Trimmed != "s3cr3t" {
+		t.Errorf("Expected field value to be '%q' but got '%q'", "s3cr3t", fileStruct.Trimmed)
+	}
+
+	if fileStruct.Preserved != "s3cr3t\r\n" {
+		t.Errorf("Expected field value to be '%q' but got '%q'", "s3cr3t\r\n", fileStruct.Preserved)
+	}
+
+	if fileStruct.Path != filename {
+		t.Errorf("Expected field value to be '%s' but got '%s'", filename, fileStruct.Path)
+	}
+
+	m = map[string]string{"FILE_SECRET": filepath.Join(dir, "missing")}
+	err = env.UnmarshalMap(m, &fileStruct)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected error 'ErrNotExist' but got '%v'", err)
+	}
+}
+
+type Hosts []string
+
+type Ports []uint16
+
+type Port uint16
+
+type Toggle bool
+
+func TestUnmarshalNamedBool(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected Toggle
+	}{
+		{"1", true},
+		{"0", false},
+		{"true", true},
+		{"false", false},
+	}
+
+	for _, testCase := range testCases {
+		var toggleStruct struct {
+			Toggle  Toggle   `env:"NAMED_TOGGLE,oneof=1|0|true|false,notempty"`
+			Pointer *Toggle  `env:"NAMED_TOGGLE"`
+			Toggles []Toggle `env:"NAMED_TOGGLES,default=1,false"`
+		}
+		err := env.UnmarshalMap(map[string]string{"NAMED_TOGGLE": testCase.value}, &toggleStruct)
+		if err != nil {
+			t.Errorf("Expected no error for '%s' but got '%s'", testCase.value, err)
+		}
+
+		if toggleStruct.Toggle != testCase.expected {
+			t.Errorf("Expected field value for '%s' to be '%t' but got '%t'", testCase.value, testCase.expected, toggleStruct.Toggle)
+		}
+
+		if toggleStruct.Pointer == nil || *toggleStruct.Pointer != testCase.expected {
+			t.Errorf("Expected field value for '%s' to be '%t' but got '%v'", testCase.value, testCase.expected, toggleStruct.Pointer)
+		}
+
+		expected := []Toggle{true, false}
+		if !reflect.DeepEqual(toggleStruct.Toggles, expected) {
+			t.Errorf("Expected field value to be '%v' but got '%v'", expected, toggleStruct.Toggles)
+		}
+	}
+
+	var invalidStruct struct {
+		Toggle Toggle `env:"NAMED_TOGGLE,oneof=1|0"`
+	}
+	err := env.UnmarshalMap(map[string]string{"NAMED_TOGGLE": "true"}, &invalidStruct)
+	if !errors.Is(err, env.ErrNotAllowed) {
+		t.Errorf("Expected error to be '%s' but got '%s'", env.ErrNotAllowed, err)
+	}
+}
+
+func TestUnmarshalNamedSlices(t *testing.T) {
+	m := map[string]string{
+		"NAMED_HOSTS":          "a,b",
+		"NAMED_PORTS":          "80,443",
+		"NAMED_OVERFLOW_PORTS": "80,70000",
+	}
+
+	var namedStruct struct {
+		Hosts Hosts  `env:"NAMED_HOSTS"`
+		Ports Ports  `env:"NAMED_PORTS"`
+		Named []Port `env:"NAMED_PORTS"`
+	}
+	err := env.UnmarshalMap(m, &namedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expectedHosts := Hosts{"a", "b"}
+	if !reflect.DeepEqual(namedStruct.Hosts, expectedHosts) {
+		t.Errorf("Expected field value to be '%q' but got '%q'", expectedHosts, namedStruct.Hosts)
+	}
+
+	expectedPorts := Ports{80, 443}
+	if !reflect.DeepEqual(namedStruct.Ports, expectedPorts) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedPorts, namedStruct.Ports)
+	}
+
+	expectedNamed := []Port{80, 443}
+	if !reflect.DeepEqual(namedStruct.Named, expectedNamed) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expectedNamed, namedStruct.Named)
+	}
+
+	var overflowStruct struct {
+		Ports Ports `env:"NAMED_OVERFLOW_PORTS"`
+	}
+	err = env.UnmarshalMap(m, &overflowStruct)
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Expected error 'ErrRange' but got '%v'", err)
+	}
+
+	expected := `NAMED_OVERFLOW_PORTS: index 1: strconv.ParseUint: parsing "70000": value out of range`
+	if err != nil && err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, err)
+	}
+}
+
+func TestUnmarshalClock(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"01:30:00", 90 * time.Minute},
+		{"05:00", 5 * time.Minute},
+		{"100:00:05", 100*time.Hour + 5*time.Second},
+	}
+
+	for _, test := range tests {
+		m := map[string]string{"CLOCK_WINDOW": test.value}
+
+		var clockStruct struct {
+			Window time.Duration `env:"CLOCK_WINDOW,clock"`
+		}
+		err := env.UnmarshalMap(m, &clockStruct)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if clockStruct.Window != test.expected {
+			t.Errorf("Expected field value to be '%s' but got '%s'", test.expected, clockStruct.Window)
+		}
+	}
+
+	for _, value := range []string{"4000000000:00:00", "2562047:59:59"} {
+		var clockStruct struct {
+			Window time.Duration `env:"CLOCK_WINDOW,clock"`
+		}
+		err := env.UnmarshalMap(map[string]string{"CLOCK_WINDOW": value}, &clockStruct)
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("Expected error 'ErrRange' for '%s' but got '%v'", value, err)
+		}
+	}
+
+	for _, value := range []string{"1h30m", "01:60:00", "1:2:3:4"} {
+		m := map[string]string{"CLOCK_WINDOW": value}
+
+		var clockStruct struct {
+			Window time.Duration `env:"CLOCK_WINDOW,clock"`
+		}
+		err := env.UnmarshalMap(m, &clockStruct)
+		if err == nil {
+			t.Errorf("Expected an error for '%s' but got none", value)
+		}
+	}
+}
+
+func TestUnmarshalDefaultTrue(t *testing.T) {
+	var absentStruct struct {
+		Cache bool `env:"DEFAULT_TRUE_CACHE,defaultTrue"`
+	}
+	err := env.UnmarshalMap(map[string]string{}, &absentStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if !absentStruct.Cache {
+		t.Errorf("Expected field value to be '%t' but got '%t'", true, absentStruct.Cache)
+	}
+
+	m := map[string]string{"DEFAULT_TRUE_CACHE": "false"}
+
+	var presentStruct struct {
+		Cache bool `env:"DEFAULT_TRUE_CACHE,defaultTrue"`
+	}
+	err = env.UnmarshalMap(m, &presentStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if presentStruct.Cache {
+		t.Errorf("Expected field value to be '%t' but got '%t'", false, presentStruct.Cache)
+	}
+}
+
+type InlineStruct struct {
+	Database struct {
+		Host string `env:"host"`
+		Port int    `env:"port,default=5432"`
+	} `env:"INLINE_DB,inline"`
+
+	Cache *struct {
+		Host string `env:"host"`
+	} `env:"INLINE_CACHE,inline,sep=&,kvsep=:"`
+}
+
+func TestUnmarshalInline(t *testing.T) {
+	m := map[string]string{
+		"INLINE_DB":    "host=localhost;port=6543",
+		"INLINE_CACHE": "host:cache.local",
+		"host":         "unused",
+	}
+
+	var inlineStruct InlineStruct
+	err := env.UnmarshalMap(m, &inlineStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if inlineStruct.Database.Host != "localhost" || inlineStruct.Database.Port != 6543 {
+		t.Errorf("Expected field values to be '%s' and '%d' but got '%s' and '%d'", "localhost", 6543, inlineStruct.Database.Host, inlineStruct.Database.Port)
+	}
+
+	if inlineStruct.Cache == nil || inlineStruct.Cache.Host != "cache.local" {
+		t.Errorf("Expected field value to be '%s' but got '%v'", "cache.local", inlineStruct.Cache)
+	}
+
+	data, err := env.Marshal(&inlineStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := "INLINE_DB=host=localhost;port=6543\nINLINE_CACHE=host:cache.local\n"
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+
+	m = map[string]string{"INLINE_DB": "host=localhost;port=port"}
+	err = env.UnmarshalMap(m, &inlineStruct)
+
+	expectedErr := `INLINE_DB: port: strconv.ParseInt: parsing "port": invalid syntax`
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected error '%s' but got '%v'", expectedErr, err)
+	}
+}
+
+func TestUnmarshalInlinePositional(t *testing.T) {
+	type Range struct {
+		Min int
+		Max int
+	}
+
+	var rangeStruct struct {
+		Range Range `env:"RANGE,inline,sep=-"`
+	}
+	err := env.UnmarshalMap(map[string]string{"RANGE": "10-20"}, &rangeStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := Range{Min: 10, Max: 20}
+	if rangeStruct.Range != expected {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expected, rangeStruct.Range)
+	}
+
+	data, err := env.Marshal(&rangeStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if string(data) != "RANGE=10-20\n" {
+		t.Errorf("Expected output to be '%s' but got '%s'", "RANGE=10-20\n", data)
+	}
+
+	err = env.UnmarshalMap(map[string]string{"RANGE": "10-20-30"}, &rangeStruct)
+	if !errors.Is(err, env.ErrInvalidLength) {
+		t.Errorf("Expected error to be '%s' but got '%s'", env.ErrInvalidLength, err)
+	}
+
+	err = env.UnmarshalMap(map[string]string{"RANGE": "10-max"}, &rangeStruct)
+
+	expectedErr := `RANGE: Max: strconv.ParseInt: parsing "max": invalid syntax`
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected error '%s' but got '%v'", expectedErr, err)
+	}
+}
+
+func TestUnmarshalSliceKeysDefault(t *testing.T) {
+	type sliceKeysStruct struct {
+		Hosts []string `env:"KEYS_HOSTS,KEYS_ALT_HOSTS,default=a,b"`
+	}
+
+	tests := []struct {
+		m        map[string]string
+		expected []string
+	}{
+		{map[string]string{"KEYS_HOSTS": "c,d", "KEYS_ALT_HOSTS": "e"}, []string{"c", "d"}},
+		{map[string]string{"KEYS_ALT_HOSTS": "e,f"}, []string{"e", "f"}},
+		{map[string]string{}, []string{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		var s sliceKeysStruct
+		err := env.UnmarshalMap(test.m, &s)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if !reflect.DeepEqual(s.Hosts, test.expected) {
+			t.Errorf("Expected field value to be '%q' but got '%q'", test.expected, s.Hosts)
+		}
+	}
+}
+
+func TestUnmarshalHex(t *testing.T) {
+	m := map[string]string{
+		"HEX_COLOR":    "ff8800",
+		"HEX_PREFIXED": "#00ff00",
+		"HEX_LARGE":    "1ff8800ff",
+	}
+
+	var hexStruct struct {
+		Color    uint32 `env:"HEX_COLOR,hex"`
+		Prefixed uint32 `env:"HEX_PREFIXED,hex"`
+	}
+	err := env.UnmarshalMap(m, &hexStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if hexStruct.Color != 0xff8800 {
+		t.Errorf("Expected field value to be '%#x' but got '%#x'", 0xff8800, hexStruct.Color)
+	}
+
+	if hexStruct.Prefixed != 0x00ff00 {
+		t.Errorf("Expected field value to be '%#x' but got '%#x'", 0x00ff00, hexStruct.Prefixed)
+	}
+
+	var largeStruct struct {
+		Color uint32 `env:"HEX_LARGE,hex"`
+	}
+	err = env.UnmarshalMap(m, &largeStruct)
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Expected error 'ErrRange' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalEmptyDefault(t *testing.T) {
+	emptyDefaultStruct := struct {
+		Empty  string `env:"EMPTY_DEFAULT_NAME,default="`
+		Preset string `env:"EMPTY_DEFAULT_NAME"`
+	}{
+		Empty:  "preset",
+		Preset: "preset",
+	}
+	err := env.UnmarshalMap(map[string]string{}, &emptyDefaultStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if emptyDefaultStruct.Empty != "" {
+		t.Errorf("Expected field value to be empty but got '%s'", emptyDefaultStruct.Empty)
+	}
+
+	if emptyDefaultStruct.Preset != "preset" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "preset", emptyDefaultStruct.Preset)
+	}
+
+	var intStruct struct {
+		Port int `env:"EMPTY_DEFAULT_PORT,default="`
+	}
+	err = env.UnmarshalMap(map[string]string{}, &intStruct)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected error 'ErrSyntax' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalClockPointer(t *testing.T) {
+	type clockPointerStruct struct {
+		Window *time.Duration `env:"CLOCK_POINTER_WINDOW,clock"`
+	}
+
+	m := map[string]string{"CLOCK_POINTER_WINDOW": "00:05:00"}
+
+	var presentStruct clockPointerStruct
+	err := env.UnmarshalMap(m, &presentStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if presentStruct.Window == nil || *presentStruct.Window != 5*time.Minute {
+		t.Errorf("Expected field value to be '%s' but got '%v'", 5*time.Minute, presentStruct.Window)
+	}
+
+	var absentStruct clockPointerStruct
+	err = env.UnmarshalMap(map[string]string{}, &absentStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if absentStruct.Window != nil {
+		t.Errorf("Expected field value to be nil but got '%s'", *absentStruct.Window)
+	}
+}
+
+func TestUnmarshalHexArray(t *testing.T) {
+	key := strings.Repeat("0f", 31) + "ff"
+	m := map[string]string{
+		"HEX_ARRAY_KEY":   key,
+		"HEX_ARRAY_SHORT": "0f0f",
+	}
+
+	var arrayStruct struct {
+		Key [32]byte `env:"HEX_ARRAY_KEY,hex"`
+	}
+	err := env.UnmarshalMap(m, &arrayStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if arrayStruct.Key[0] != 0x0f || arrayStruct.Key[31] != 0xff {
+		t.Errorf("Expected field value to be '%s' but got '%x'", key, arrayStruct.Key)
+	}
+
+	data, err := env.Marshal(&arrayStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if expected := "HEX_ARRAY_KEY=" + key + "\n"; string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+
+	var shortStruct struct {
+		Key [32]byte `env:"HEX_ARRAY_SHORT,hex"`
+	}
+	err = env.UnmarshalMap(m, &shortStruct)
+	if !errors.Is(err, env.ErrInvalidLength) {
+		t.Errorf("Expected error 'ErrInvalidLength' but got '%v'", err)
+	}
+
+	var plainStruct struct {
+		Key [32]byte `env:"HEX_ARRAY_KEY"`
+	}
+	err = env.UnmarshalMap(m, &plainStruct)
+	if err != env.ErrUnsupportedType {
+		t.Errorf("Expected error 'ErrUnsupportedType' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalEchoTrue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"MODE", true},
+		{"mode", true},
+		{"false", false},
+	}
+
+	for _, test := range tests {
+		m := map[string]string{"MODE": test.value}
+
+		var echoStruct struct {
+			Mode bool `env:"MODE,echoTrue"`
+		}
+		err := env.UnmarshalMap(m, &echoStruct)
+		if err != nil {
+			t.Errorf("Expected no error but got '%s'", err)
+		}
+
+		if echoStruct.Mode != test.expected {
+			t.Errorf("Expected field value for '%s' to be '%t' but got '%t'", test.value, test.expected, echoStruct.Mode)
+		}
+	}
+}
+
+func TestUnmarshalSliceMaxLen(t *testing.T) {
+	m := map[string]string{
+		"MAXLEN_ITEMS": "1,2,3",
+		"MAXLEN_MANY":  strings.Repeat("1,", 100) + "1",
+	}
+
+	var maxLenStruct struct {
+		Items []int `env:"MAXLEN_ITEMS,maxlen=3"`
+	}
+	err := env.UnmarshalMap(m, &maxLenStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(maxLenStruct.Items, expected) {
+		t.Errorf("Expected field value to be '%v' but got '%v'", expected, maxLenStruct.Items)
+	}
+
+	var exceededStruct struct {
+		Items []int `env:"MAXLEN_MANY,maxlen=100"`
+	}
+	err = env.UnmarshalMap(m, &exceededStruct)
+	if !errors.Is(err, env.ErrOutOfRange) {
+		t.Errorf("Expected error 'ErrOutOfRange' but got '%v'", err)
+	}
+
+	expectedErr := "MAXLEN_MANY: value is out of range: 101 elements, maxlen 100"
+	if err != nil && err.Error() != expectedErr {
+		t.Errorf("Expected error '%s' but got '%s'", expectedErr, err)
+	}
+
+	if exceededStruct.Items != nil {
+		t.Errorf("Expected field value to be nil but got '%v'", exceededStruct.Items)
+	}
+}
+
+func TestUnmarshalTimeZone(t *testing.T) {
+	m := map[string]string{"TZ_START": "2016-07-15 12:00:00"}
+
+	var tzStruct struct {
+		UTC   time.Time `env:"TZ_START,layout=2006-01-02 15:04:05"`
+		Tokyo time.Time `env:"TZ_START,layout=2006-01-02 15:04:05,tz=Asia/Tokyo"`
+	}
+	err := env.UnmarshalMap(m, &tzStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := time.Date(2016, 7, 15, 12, 0, 0, 0, time.UTC)
+	if !tzStruct.UTC.Equal(expected) {
+		t.Errorf("Expected field value to be '%s' but got '%s'", expected, tzStruct.UTC)
+	}
+
+	expected = time.Date(2016, 7, 15, 3, 0, 0, 0, time.UTC)
+	if !tzStruct.Tokyo.Equal(expected) {
+		t.Errorf("Expected field value to be '%s' but got '%s'", expected, tzStruct.Tokyo)
+	}
+
+	var invalidStruct struct {
+		Start time.Time `env:"TZ_START,layout=2006-01-02 15:04:05,tz=Nowhere/Invalid"`
+	}
+	err = env.UnmarshalMap(m, &invalidStruct)
+	if !errors.Is(err, env.ErrInvalidTag) {
+		t.Errorf("Expected error 'ErrInvalidTag' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalUnsupportedCollections(t *testing.T) {
+	m := map[string]string{
+		"UNSUPPORTED_MAP":   "0.5=a",
+		"UNSUPPORTED_CHANS": "a",
+	}
+
+	var mapStruct struct {
+		Map map[float64]string `env:"UNSUPPORTED_MAP"`
+	}
+	err := env.UnmarshalMap(m, &mapStruct)
+	if !errors.Is(err, env.ErrUnsupportedType) {
+		t.Errorf("Expected error 'ErrUnsupportedType' but got '%v'", err)
+	}
+
+	var chanStruct struct {
+		Chans []chan string `env:"UNSUPPORTED_CHANS"`
+	}
+	err = env.UnmarshalMap(m, &chanStruct)
+	if !errors.Is(err, env.ErrUnsupportedType) {
+		t.Errorf("Expected error 'ErrUnsupportedType' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalBase(t *testing.T) {
+	m := map[string]string{
+		"BASE_MASK":   "ff",
+		"BASE_BITS":   "1011",
+		"BASE_AUTO":   "0x1f",
+		"BASE_SIGNED": "-7f",
+	}
+
+	var baseStruct struct {
+		Mask   uint32 `env:"BASE_MASK,base=16"`
+		Bits   uint8  `env:"BASE_BITS,base=2"`
+		Auto   int    `env:"BASE_AUTO,base=0"`
+		Signed int16  `env:"BASE_SIGNED,base=16"`
+	}
+	err := env.UnmarshalMap(m, &baseStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	testCases := [][]interface{}{
+		{baseStruct.Mask, uint32(255)},
+		{baseStruct.Bits, uint8(11)},
+		{baseStruct.Auto, 31},
+		{baseStruct.Signed, int16(-127)},
+	}
+
+	for _, testCase := range testCases {
+		if testCase[0] != testCase[1] {
+			t.Errorf("Expected field value to be '%v' but got '%v'", testCase[1], testCase[0])
+		}
+	}
+
+	var invalidStruct struct {
+		Mask uint32 `env:"BASE_MASK,base=1"`
+	}
+	err = env.UnmarshalMap(m, &invalidStruct)
+	if !errors.Is(err, env.ErrInvalidTag) {
+		t.Errorf("Expected error 'ErrInvalidTag' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalAllPointers(t *testing.T) {
+	m := map[string]string{
+		"PATCH_NAME":    "name",
+		"PATCH_PORT":    "8080",
+		"PATCH_ENABLED": "false",
+	}
+
+	var patchStruct struct {
+		Name    *string        `env:"PATCH_NAME"`
+		Port    *int           `env:"PATCH_PORT"`
+		Enabled *bool          `env:"PATCH_ENABLED"`
+		Host    *string        `env:"PATCH_HOST"`
+		Timeout *time.Duration `env:"PATCH_TIMEOUT"`
+		Ratio   *float64       `env:"PATCH_RATIO"`
+	}
+	err := env.UnmarshalMap(m, &patchStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if patchStruct.Name == nil || *patchStruct.Name != "name" {
+		t.Errorf("Expected field value to be '%s' but got '%v'", "name", patchStruct.Name)
+	}
+
+	if patchStruct.Port == nil || *patchStruct.Port != 8080 {
+		t.Errorf("Expected field value to be '%d' but got '%v'", 8080, patchStruct.Port)
+	}
+
+	if patchStruct.Enabled == nil || *patchStruct.Enabled {
+		t.Errorf("Expected field value to be '%t' but got '%v'", false, patchStruct.Enabled)
+	}
+
+	if patchStruct.Host != nil || patchStruct.Timeout != nil || patchStruct.Ratio != nil {
+		t.Errorf("Expected absent fields to be nil but got '%v', '%v' and '%v'", patchStruct.Host, patchStruct.Timeout, patchStruct.Ratio)
+	}
+}
+
+func TestUnmarshalDurationUnit(t *testing.T) {
+	var unitStruct struct {
+		Timeout  time.Duration `env:"UNIT_TIMEOUT,unit=s,default=30"`
+		Interval time.Duration `env:"UNIT_INTERVAL,unit=ms"`
+	}
+
+	err := env.UnmarshalMap(map[string]string{"UNIT_INTERVAL": "250"}, &unitStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if unitStruct.Timeout != 30*time.Second {
+		t.Errorf("Expected field value to be '%s' but got '%s'", 30*time.Second, unitStruct.Timeout)
+	}
+
+	if unitStruct.Interval != 250*time.Millisecond {
+		t.Errorf("Expected field value to be '%s' but got '%s'", 250*time.Millisecond, unitStruct.Interval)
+	}
+
+	err = env.UnmarshalMap(map[string]string{"UNIT_TIMEOUT": "45"}, &unitStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if unitStruct.Timeout != 45*time.Second {
+		t.Errorf("Expected field value to be '%s' but got '%s'", 45*time.Second, unitStruct.Timeout)
+	}
+
+	var hourStruct struct {
+		Retention time.Duration `env:"UNIT_RETENTION,unit=h"`
+	}
+	for _, value := range []string{"9999999999", "-9999999999"} {
+		err = env.UnmarshalMap(map[string]string{"UNIT_RETENTION": value}, &hourStruct)
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("Expected error 'ErrRange' for '%s' but got '%v'", value, err)
+		}
+	}
+
+	err = env.UnmarshalMap(map[string]string{"UNIT_RETENTION": "2562047"}, &hourStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+}
+
+type IgnoredFieldStruct struct {
+	Name    string `env:"IGNORED_NAME"`
+	Ignored string `env:"-"`
+}
+
+func TestUnmarshalIgnoredField(t *testing.T) {
+	m := map[string]string{"IGNORED_NAME": "service", "-": "ignored"}
+
+	var ignoredStruct IgnoredFieldStruct
+	err := env.UnmarshalMap(m, &ignoredStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if ignoredStruct.Name != "service" || ignoredStruct.Ignored != "" {
+		t.Errorf("Expected field values to be '%s' and empty but got '%s' and '%s'", "service", ignoredStruct.Name, ignoredStruct.Ignored)
+	}
+
+	ignoredStruct = IgnoredFieldStruct{}
+	err = env.NewDecoder(env.WithSource(env.Map(m)), env.WithErrorOnDuplicateKeys()).Unmarshal(&ignoredStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if ignoredStruct.Ignored != "" {
+		t.Errorf("Expected field value to be empty but got '%s'", ignoredStruct.Ignored)
+	}
+
+	ignoredStruct.Ignored = "ignored"
+	data, err := env.Marshal(&ignoredStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := "IGNORED_NAME=service\n"
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+}
@@ -0,0 +1,28 @@
+package env
+
+// FieldError is returned when the variable of a field cannot be decoded
+// or is invalid.
+type FieldError struct {
+	// Field is the dotted Go path of the field.
+	Field string
+
+	// Key is the variable the value was read from, or the primary key of
+	// the field if its variables are missing.
+	Key string
+
+	// Err is the underlying error.
+	Err error
+
+	format func(fieldPath, key string, err error) string
+}
+
+func (e *FieldError) Error() string {
+	if e.format != nil {
+		return e.format(e.Field, e.Key, e.Err)
+	}
+	return e.Key + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,97 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetString returns the value of the environment variable key, or def if
+// it is not set.
+func GetString(key, def string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return def
+}
+
+// GetInt returns the value of the environment variable key as an int, or
+// def if it is not set or not an integer.
+func GetInt(key string, def int) int {
+	v, ok, err := LookupInt(key)
+	if !ok || err != nil {
+		return def
+	}
+	return v
+}
+
+// GetBool returns the value of the environment variable key as a bool, or
+// def if it is not set or not accepted by strconv.ParseBool.
+func GetBool(key string, def bool) bool {
+	v, ok, err := LookupBool(key)
+	if !ok || err != nil {
+		return def
+	}
+	return v
+}
+
+// GetDuration returns the value of the environment variable key as a
+// time.Duration, or def if it is not set or not accepted by
+// time.ParseDuration.
+func GetDuration(key string, def time.Duration) time.Duration {
+	v, ok, err := LookupDuration(key)
+	if !ok || err != nil {
+		return def
+	}
+	return v
+}
+
+// LookupInt returns the value of the environment variable key as an int.
+// It reports false if the variable is not set, and returns an error
+// prefixed with key if the value is not an integer.
+func LookupInt(key string) (int, bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, true, fmt.Errorf("%s: %w", key, err)
+	}
+	return v, true, nil
+}
+
+// LookupBool returns the value of the environment variable key as a bool.
+// It reports false if the variable is not set, and returns an error
+// prefixed with key if the value is not accepted by strconv.ParseBool.
+func LookupBool(key string) (bool, bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return false, false, nil
+	}
+
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, true, fmt.Errorf("%s: %w", key, err)
+	}
+	return v, true, nil
+}
+
+// LookupDuration returns the value of the environment variable key as a
+// time.Duration. It reports false if the variable is not set, and returns
+// an error prefixed with key if the value is not accepted by
+// time.ParseDuration.
+func LookupDuration(key string) (time.Duration, bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	v, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, true, fmt.Errorf("%s: %w", key, err)
+	}
+	return v, true, nil
+}
@@ -0,0 +1,156 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+func TestGetString(t *testing.T) {
+	_ = os.Setenv("GET_STRING", "value")
+	defer os.Unsetenv("GET_STRING")
+
+	if v := env.GetString("GET_STRING", "default"); v != "value" {
+		t.Errorf("Expected value to be '%s' but got '%s'", "value", v)
+	}
+
+	if v := env.GetString("GET_STRING_MISSING", "default"); v != "default" {
+		t.Errorf("Expected value to be '%s' but got '%s'", "default", v)
+	}
+}
+
+func TestGetInt(t *testing.T) {
+	_ = os.Setenv("GET_INT", "42")
+	_ = os.Setenv("GET_INT_MALFORMED", "forty-two")
+	defer os.Unsetenv("GET_INT")
+	defer os.Unsetenv("GET_INT_MALFORMED")
+
+	testCases := []struct {
+		key      string
+		expected int
+	}{
+		{"GET_INT", 42},
+		{"GET_INT_MISSING", 7},
+		{"GET_INT_MALFORMED", 7},
+	}
+
+	for _, testCase := range testCases {
+		if v := env.GetInt(testCase.key, 7); v != testCase.expected {
+			t.Errorf("%s: Expected value to be '%d' but got '%d'", testCase.key, testCase.expected, v)
+		}
+	}
+}
+
+func TestGetBool(t *testing.T) {
+	_ = os.Setenv("GET_BOOL", "false")
+	_ = os.Setenv("GET_BOOL_MALFORMED", "maybe")
+	defer os.Unsetenv("GET_BOOL")
+	defer os.Unsetenv("GET_BOOL_MALFORMED")
+
+	testCases := []struct {
+		key      string
+		expected bool
+	}{
+		{"GET_BOOL", false},
+		{"GET_BOOL_MISSING", true},
+		{"GET_BOOL_MALFORMED", true},
+	}
+
+	for _, testCase := range testCases {
+		if v := env.GetBool(testCase.key, true); v != testCase.expected {
+			t.Errorf("%s: Expected value to be '%t' but got '%t'", testCase.key, testCase.expected, v)
+		}
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	_ = os.Setenv("GET_DURATION", "5s")
+	_ = os.Setenv("GET_DURATION_MALFORMED", "5")
+	defer os.Unsetenv("GET_DURATION")
+	defer os.Unsetenv("GET_DURATION_MALFORMED")
+
+	testCases := []struct {
+		key      string
+		expected time.Duration
+	}{
+		{"GET_DURATION", 5 * time.Second},
+		{"GET_DURATION_MISSING", time.Minute},
+		{"GET_DURATION_MALFORMED", time.Minute},
+	}
+
+	for _, testCase := range testCases {
+		if v := env.GetDuration(testCase.key, time.Minute); v != testCase.expected {
+			t.Errorf("%s: Expected value to be '%s' but got '%s'", testCase.key, testCase.expected, v)
+		}
+	}
+}
+
+func TestLookupInt(t *testing.T) {
+	_ = os.Setenv("LOOKUP_INT", "42")
+	_ = os.Setenv("LOOKUP_INT_MALFORMED", "forty-two")
+	defer os.Unsetenv("LOOKUP_INT")
+	defer os.Unsetenv("LOOKUP_INT_MALFORMED")
+
+	v, ok, err := env.LookupInt("LOOKUP_INT")
+	if v != 42 || !ok || err != nil {
+		t.Errorf("Expected '%d, %t, %v' but got '%d, %t, %v'", 42, true, nil, v, ok, err)
+	}
+
+	v, ok, err = env.LookupInt("LOOKUP_INT_MALFORMED")
+	if !ok || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected '%t' and error 'ErrSyntax' but got '%t, %v'", true, ok, err)
+	}
+
+	v, ok, err = env.LookupInt("LOOKUP_INT_MISSING")
+	if v != 0 || ok || err != nil {
+		t.Errorf("Expected '%d, %t, %v' but got '%d, %t, %v'", 0, false, nil, v, ok, err)
+	}
+}
+
+func TestLookupBool(t *testing.T) {
+	_ = os.Setenv("LOOKUP_BOOL", "true")
+	_ = os.Setenv("LOOKUP_BOOL_MALFORMED", "maybe")
+	defer os.Unsetenv("LOOKUP_BOOL")
+	defer os.Unsetenv("LOOKUP_BOOL_MALFORMED")
+
+	v, ok, err := env.LookupBool("LOOKUP_BOOL")
+	if !v || !ok || err != nil {
+		t.Errorf("Expected '%t, %t, %v' but got '%t, %t, %v'", true, true, nil, v, ok, err)
+	}
+
+	_, ok, err = env.LookupBool("LOOKUP_BOOL_MALFORMED")
+	if !ok || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected '%t' and error 'ErrSyntax' but got '%t, %v'", true, ok, err)
+	}
+
+	v, ok, err = env.LookupBool("LOOKUP_BOOL_MISSING")
+	if v || ok || err != nil {
+		t.Errorf("Expected '%t, %t, %v' but got '%t, %t, %v'", false, false, nil, v, ok, err)
+	}
+}
+
+func TestLookupDuration(t *testing.T) {
+	_ = os.Setenv("LOOKUP_DURATION", "5s")
+	_ = os.Setenv("LOOKUP_DURATION_MALFORMED", "5")
+	defer os.Unsetenv("LOOKUP_DURATION")
+	defer os.Unsetenv("LOOKUP_DURATION_MALFORMED")
+
+	v, ok, err := env.LookupDuration("LOOKUP_DURATION")
+	if v != 5*time.Second || !ok || err != nil {
+		t.Errorf("Expected '%s, %t, %v' but got '%s, %t, %v'", 5*time.Second, true, nil, v, ok, err)
+	}
+
+	_, ok, err = env.LookupDuration("LOOKUP_DURATION_MALFORMED")
+	if !ok || err == nil {
+		t.Errorf("Expected '%t' and an error but got '%t, %v'", true, ok, err)
+	}
+
+	v, ok, err = env.LookupDuration("LOOKUP_DURATION_MISSING")
+	if v != 0 || ok || err != nil {
+		t.Errorf("Expected '%s, %t, %v' but got '%s, %t, %v'", time.Duration(0), false, nil, v, ok, err)
+	}
+}
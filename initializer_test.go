@@ -0,0 +1,63 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+type DatabaseConfig struct {
+	Host string `env:"DB_HOST"`
+	Port string `env:"DB_PORT"`
+
+	Address string
+}
+
+func (c *DatabaseConfig) AfterUnmarshal() error {
+	if c.Host == "" {
+		return errors.New("database host is empty")
+	}
+	c.Address = c.Host + ":" + c.Port
+	return nil
+}
+
+type InitializerStruct struct {
+	Database DatabaseConfig
+
+	Summary string
+}
+
+func (c *InitializerStruct) AfterUnmarshal() error {
+	c.Summary = "database at " + c.Database.Address
+	return nil
+}
+
+func TestUnmarshalInitializer(t *testing.T) {
+	m := map[string]string{
+		"DB_HOST": "localhost",
+		"DB_PORT": "5432",
+	}
+
+	var initializerStruct InitializerStruct
+	err := env.UnmarshalMap(m, &initializerStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if initializerStruct.Database.Address != "localhost:5432" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "localhost:5432", initializerStruct.Database.Address)
+	}
+
+	if initializerStruct.Summary != "database at localhost:5432" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "database at localhost:5432", initializerStruct.Summary)
+	}
+}
+
+func TestUnmarshalInitializerError(t *testing.T) {
+	var initializerStruct InitializerStruct
+	err := env.UnmarshalMap(map[string]string{"DB_PORT": "5432"}, &initializerStruct)
+	if err == nil || err.Error() != "database host is empty" {
+		t.Errorf("Expected error '%s' but got '%v'", "database host is empty", err)
+	}
+}
@@ -0,0 +1,174 @@
+package env
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// secretMask replaces the values of secret fields in MarshalJSON.
+const secretMask = "******"
+
+// MarshalJSON returns the fields tagged with "env" of the structure v, or
+// pointed to by v, as a JSON object mapping their primary keys to their
+// values. Nested structures are objects under their field names, and the
+// fields of embedded structures are inlined. Fields tagged as secret are
+// masked, and nil pointers are null.
+//
+// Numbers, bools, strings and their slices and maps keep their JSON
+// types. Durations and other values are strings, in the form written by
+// Marshal. MarshalJSON returns the same errors as Marshal.
+func MarshalJSON(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, ErrInvalidValue
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrInvalidValue
+	}
+
+	object := make(map[string]interface{})
+	err := marshalJSON(object, rv, "")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(object)
+}
+
+func marshalJSON(object map[string]interface{}, rv reflect.Value, prefix string) error {
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		valueField := rv.Field(i)
+		typeField := t.Field(i)
+
+		if isEmbeddedStructPtr(typeField) {
+			if valueField.IsNil() {
+				continue
+			}
+			valueField = valueField.Elem()
+		}
+		if valueField.Kind() == reflect.Struct && typeField.PkgPath == "" && hasTaggedFields(valueField.Type()) && !isInline(typeField) {
+			nested := object
+			if !typeField.Anonymous {
+				nested = make(map[string]interface{})
+				object[typeField.Name] = nested
+			}
+			err := marshalJSON(nested, valueField, prefix+typeField.Tag.Get("envPrefix"))
+			if err != nil {
+				return err
+			}
+		}
+
+		tag := typeField.Tag.Get("env")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		if typeField.PkgPath != "" {
+			return ErrUnexportedField
+		}
+
+		envTag := parseTag(tag).withPrefix(prefix)
+		key := envTag.key()
+
+		if strings.HasSuffix(key, "*") {
+			m, ok := valueField.Interface().(map[string]string)
+			if !ok {
+				return ErrUnsupportedType
+			}
+			for k, v := range m {
+				object[k] = v
+			}
+			continue
+		}
+
+		value, err := jsonValue(valueField, envTag)
+		if err != nil {
+			return err
+		}
+		if envTag.Secret && value != nil {
+			value = secretMask
+		}
+		object[key] = value
+	}
+
+	return nil
+}
+
+// jsonValue returns the value of f encoded by MarshalJSON.
+func jsonValue(f reflect.Value, envTag tag) (interface{}, error) {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil, nil
+		}
+		f = f.Elem()
+	}
+	f = loadAtomic(f)
+
+	switch f.Kind() {
+	case reflect.Bool:
+		return f.Bool() != envTag.Negate, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isDuration(f.Type()) {
+			return formatValue(f, envTag)
+		}
+		return f.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return f.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return f.Float(), nil
+	case reflect.String:
+		return f.String(), nil
+	case reflect.Slice:
+		values := make([]interface{}, f.Len())
+		for i := range values {
+			value, err := jsonValue(f.Index(i), envTag)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case reflect.Map:
+		if !isMapKey(f.Type().Key()) {
+			return nil, ErrUnsupportedType
+		}
+		if isEmptyStruct(f.Type().Elem()) {
+			keys := sortedMapKeys(f)
+			values := make([]interface{}, len(keys))
+			for i, key := range keys {
+				value, err := jsonValue(key, tag{})
+				if err != nil {
+					return nil, err
+				}
+				values[i] = value
+			}
+			return values, nil
+		}
+		values := make(map[string]interface{}, f.Len())
+		iter := f.MapRange()
+		for iter.Next() {
+			key, err := formatValue(iter.Key(), tag{})
+			if err != nil {
+				return nil, err
+			}
+			value, err := jsonValue(iter.Value(), envTag)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+		return values, nil
+	}
+
+	if t, ok := f.Interface().(time.Time); ok && len(envTag.Layouts) == 0 {
+		return t, nil
+	}
+	return formatValue(f, envTag)
+}
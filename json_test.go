@@ -0,0 +1,69 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+type JSONStruct struct {
+	Host     string  `env:"HOST,ALT_HOST"`
+	Password string  `env:"PASSWORD,secret"`
+	Token    *string `env:"TOKEN,secret"`
+
+	Server struct {
+		Port    int           `env:"PORT"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Debug   bool          `env:"DEBUG"`
+	}
+
+	Tags   []string            `env:"TAGS"`
+	Labels map[string]string   `env:"LABELS"`
+	Flags  map[string]struct{} `env:"FLAGS"`
+	Ratio  float64             `env:"RATIO"`
+	Extra  string
+}
+
+func TestMarshalJSON(t *testing.T) {
+	var jsonStruct JSONStruct
+	jsonStruct.Host = "localhost"
+	jsonStruct.Password = "hunter2"
+	jsonStruct.Server.Port = 8080
+	jsonStruct.Server.Timeout = 5 * time.Second
+	jsonStruct.Server.Debug = true
+	jsonStruct.Tags = []string{"a", "b"}
+	jsonStruct.Labels = map[string]string{"tier": "1"}
+	jsonStruct.Flags = map[string]struct{}{"b": {}, "a": {}}
+	jsonStruct.Ratio = 0.5
+	jsonStruct.Extra = "extra"
+
+	data, err := env.MarshalJSON(&jsonStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := `{"FLAGS":["a","b"],"HOST":"localhost","LABELS":{"tier":"1"},"PASSWORD":"******","RATIO":0.5,` +
+		`"Server":{"DEBUG":true,"PORT":8080,"TIMEOUT":"5s"},"TAGS":["a","b"],"TOKEN":null}`
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+}
+
+func TestMarshalJSONInvalidValue(t *testing.T) {
+	_, err := env.MarshalJSON("value")
+	if !errors.Is(err, env.ErrInvalidValue) {
+		t.Errorf("Expected error to be '%s' but got '%s'", env.ErrInvalidValue, err)
+	}
+}
+
+func TestMarshalJSONUnsupportedCatchAll(t *testing.T) {
+	var stringStruct struct {
+		M string `env:"X_*"`
+	}
+	_, err := env.MarshalJSON(stringStruct)
+	if err != env.ErrUnsupportedType {
+		t.Errorf("Expected error 'ErrUnsupportedType' but got '%v'", err)
+	}
+}
@@ -0,0 +1,332 @@
+package env
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal returns the fields tagged with "env" of the structure v, or
+// pointed to by v, in the .env format read by Parse. Each field is written
+// under its primary key, in declaration order. Nil pointers are omitted.
+//
+// If v is not a structure or a pointer to a structure, Marshal returns
+// ErrInvalidValue. If a field cannot be formatted, Marshal returns
+// ErrUnsupportedType. If a key is not a valid shell identifier, Marshal
+// returns an error wrapping ErrInvalidKey, unless WithLaxKeys is used; the
+// other options are ignored.
+func Marshal(v interface{}, opts ...ParseOption) ([]byte, error) {
+	p := &dotEnvParser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, ErrInvalidValue
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrInvalidValue
+	}
+
+	var buf bytes.Buffer
+	err := marshal(&buf, rv, "", p.laxKeys)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshal(buf *bytes.Buffer, rv reflect.Value, prefix string, laxKeys bool) error {
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		valueField := rv.Field(i)
+		typeField := t.Field(i)
+
+		if isEmbeddedStructPtr(typeField) {
+			if valueField.IsNil() {
+				continue
+			}
+			valueField = valueField.Elem()
+		}
+		if valueField.Kind() == reflect.Struct && typeField.PkgPath == "" && hasTaggedFields(valueField.Type()) && !isInline(typeField) {
+			err := marshal(buf, valueField, prefix+typeField.Tag.Get("envPrefix"), laxKeys)
+			if err != nil {
+				return err
+			}
+		}
+
+		tag := typeField.Tag.Get("env")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		if typeField.PkgPath != "" {
+			return ErrUnexportedField
+		}
+
+		envTag := parseTag(tag).withPrefix(prefix)
+		key := envTag.key()
+
+		if strings.HasSuffix(key, "*") {
+			err := marshalCatchAll(buf, valueField, laxKeys)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !laxKeys && !isValidKey(key) {
+			return fmt.Errorf("%s: %w", key, ErrInvalidKey)
+		}
+
+		value, ok, err := format(valueField, envTag)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		writeVariable(buf, key, value)
+	}
+
+	return nil
+}
+
+// marshalCatchAll writes the variables of a catch-all map in key order.
+// Keys that are not valid shell identifiers are rejected unless laxKeys.
+func marshalCatchAll(buf *bytes.Buffer, f reflect.Value, laxKeys bool) error {
+	m, ok := f.Interface().(map[string]string)
+	if !ok {
+		return ErrUnsupportedType
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !laxKeys && !isValidKey(key) {
+			return fmt.Errorf("%s: %w", key, ErrInvalidKey)
+		}
+		writeVariable(buf, key, m[key])
+	}
+	return nil
+}
+
+// writeVariable writes a key=value line, quoting values that Parse would
+// otherwise change.
+func writeVariable(buf *bytes.Buffer, key, value string) {
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, "#\"'") {
+		value = `"` + value + `"`
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// formatInline returns the tagged fields of the structure f as entries read
+// by the inline option, omitting nil pointers.
+func formatInline(f reflect.Value, envTag tag) (string, error) {
+	sep := envTag.Sep
+	if sep == "" {
+		sep = ";"
+	}
+
+	if fields := positionalFields(f.Type()); fields != nil {
+		values := make([]string, len(fields))
+		for i, index := range fields {
+			value, _, err := format(f.Field(index), tag{})
+			if err != nil {
+				return "", err
+			}
+			values[i] = value
+		}
+		return strings.Join(values, sep), nil
+	}
+
+	var entries []string
+	for i := 0; i < f.NumField(); i++ {
+		tagString := f.Type().Field(i).Tag.Get("env")
+		if tagString == "" || tagString == "-" {
+			continue
+		}
+
+		fieldTag := parseTag(tagString)
+		value, ok, err := format(f.Field(i), fieldTag)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			entries = append(entries, fieldTag.key()+envTag.kvSep()+value)
+		}
+	}
+	return strings.Join(entries, sep), nil
+}
+
+// sortedMapKeys returns the keys of the map f in order, numbers by value
+// and false before true.
+func sortedMapKeys(f reflect.Value) []reflect.Value {
+	keys := f.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		switch keys[i].Kind() {
+		case reflect.Bool:
+			return !keys[i].Bool() && keys[j].Bool()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return keys[i].Int() < keys[j].Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return keys[i].Uint() < keys[j].Uint()
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// format returns the value of field f as read by Unmarshal with envTag.
+// It reports false if f is a nil pointer.
+func format(f reflect.Value, envTag tag) (string, bool, error) {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return "", false, nil
+		}
+		f = f.Elem()
+	}
+
+	value, err := formatValue(f, envTag)
+	return value, err == nil, err
+}
+
+func formatValue(f reflect.Value, envTag tag) (string, error) {
+	f = loadAtomic(f)
+
+	switch f.Kind() {
+	case reflect.Ptr:
+		if f.IsNil() {
+			return "", nil
+		}
+		return formatValue(f.Elem(), envTag)
+	case reflect.Slice:
+		parts := make([]string, f.Len())
+		for i := range parts {
+			part, err := formatValue(f.Index(i), envTag)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, envTag.sep()), nil
+	case reflect.Array:
+		if f.Type().Elem().Kind() != reflect.Uint8 || !envTag.Hex {
+			return "", ErrUnsupportedType
+		}
+		b := make([]byte, f.Len())
+		reflect.Copy(reflect.ValueOf(b), f)
+		return hex.EncodeToString(b), nil
+	case reflect.Map:
+		if !isMapKey(f.Type().Key()) {
+			return "", ErrUnsupportedType
+		}
+		keys := sortedMapKeys(f)
+
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			keyValue, err := formatValue(key, tag{})
+			if err != nil {
+				return "", err
+			}
+			if isEmptyStruct(f.Type().Elem()) {
+				parts[i] = keyValue
+				continue
+			}
+			value, err := formatValue(f.MapIndex(key), envTag)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = keyValue + envTag.kvSep() + value
+		}
+		return strings.Join(parts, envTag.sep()), nil
+	case reflect.String:
+		if envTag.Base64 {
+			return base64.StdEncoding.EncodeToString([]byte(f.String())), nil
+		}
+		return f.String(), nil
+	case reflect.Bool:
+		v := f.Bool() != envTag.Negate
+		if envTag.NumericBool {
+			if v {
+				return "1", nil
+			}
+			return "0", nil
+		}
+		return strconv.FormatBool(v), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(f.Float(), 'g', -1, f.Type().Bits()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isDuration(f.Type()) {
+			d := time.Duration(f.Int())
+			if envTag.Clock {
+				return formatClock(d), nil
+			}
+			if envTag.SecFloat {
+				return strconv.FormatFloat(d.Seconds(), 'g', -1, 64), nil
+			}
+			if unit, ok := durationUnits[envTag.Unit]; ok {
+				return strconv.FormatInt(int64(d/unit), 10), nil
+			}
+			return d.String(), nil
+		}
+		base, err := envTag.base()
+		if err != nil {
+			return "", err
+		}
+		if base == 0 {
+			base = 10
+		}
+		return strconv.FormatInt(f.Int(), base), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		base, err := envTag.base()
+		if err != nil {
+			return "", err
+		}
+		if base == 0 {
+			base = 10
+		}
+		return strconv.FormatUint(f.Uint(), base), nil
+	}
+
+	if f.Kind() == reflect.Struct && envTag.Inline {
+		return formatInline(f, envTag)
+	}
+
+	if t, ok := f.Interface().(time.Time); ok && len(envTag.Layouts) > 0 {
+		loc, err := envTag.location()
+		if err != nil {
+			return "", err
+		}
+		return t.In(loc).Format(timeLayout(envTag.Layouts[0])), nil
+	}
+
+	if stringer, ok := f.Interface().(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+	if f.CanAddr() {
+		if stringer, ok := f.Addr().Interface().(fmt.Stringer); ok {
+			return stringer.String(), nil
+		}
+	}
+	return "", ErrUnsupportedType
+}
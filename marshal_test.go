@@ -0,0 +1,124 @@
+package env_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+type MarshalStruct struct {
+	Host string `env:"HOST,ALT_HOST"`
+
+	Server struct {
+		Port    int           `env:"PORT"`
+		Timeout time.Duration `env:"TIMEOUT"`
+	}
+
+	Tags    []string          `env:"TAGS,sep=;"`
+	Labels  map[string]string `env:"LABELS"`
+	Comment string            `env:"COMMENT"`
+	Missing *string           `env:"MISSING"`
+	Mask    uint32            `env:"MASK,base=16"`
+	Cache   bool              `env:"DISABLE_CACHE,negate"`
+	Extra   string
+}
+
+func TestMarshal(t *testing.T) {
+	var marshalStruct MarshalStruct
+	marshalStruct.Host = "localhost"
+	marshalStruct.Server.Port = 8080
+	marshalStruct.Server.Timeout = 5 * time.Second
+	marshalStruct.Tags = []string{"a", "b"}
+	marshalStruct.Labels = map[string]string{"tier": "1", "team": "core"}
+	marshalStruct.Comment = "value # not a comment"
+	marshalStruct.Mask = 255
+	marshalStruct.Cache = true
+	marshalStruct.Extra = "extra"
+
+	data, err := env.Marshal(&marshalStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := `HOST=localhost
+PORT=8080
+TIMEOUT=5s
+TAGS=a;b
+LABELS=team=core,tier=1
+COMMENT="value # not a comment"
+MASK=ff
+DISABLE_CACHE=false
+`
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+
+	m, err := env.Parse(strings.NewReader(string(data)))
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	var parsedStruct MarshalStruct
+	err = env.UnmarshalMap(m, &parsedStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if parsedStruct.Cache != marshalStruct.Cache {
+		t.Errorf("Expected parsed value to be '%t' but got '%t'", marshalStruct.Cache, parsedStruct.Cache)
+	}
+
+	if parsedStruct.Comment != marshalStruct.Comment || parsedStruct.Mask != marshalStruct.Mask {
+		t.Errorf("Expected parsed values to be '%s' and '%d' but got '%s' and '%d'", marshalStruct.Comment, marshalStruct.Mask, parsedStruct.Comment, parsedStruct.Mask)
+	}
+}
+
+func TestMarshalInvalidKey(t *testing.T) {
+	var invalidStruct struct {
+		Foo string `env:"1FOO"`
+	}
+	_, err := env.Marshal(invalidStruct)
+	if !errors.Is(err, env.ErrInvalidKey) {
+		t.Errorf("Expected error 'ErrInvalidKey' but got '%v'", err)
+	}
+
+	var catchAllStruct struct {
+		Extra map[string]string `env:"*"`
+	}
+	catchAllStruct.Extra = map[string]string{"my-key": "value"}
+	_, err = env.Marshal(catchAllStruct)
+	if !errors.Is(err, env.ErrInvalidKey) {
+		t.Errorf("Expected error 'ErrInvalidKey' but got '%v'", err)
+	}
+
+	data, err := env.Marshal(catchAllStruct, env.WithLaxKeys())
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := "my-key=value\n"
+	if string(data) != expected {
+		t.Errorf("Expected output to be '%s' but got '%s'", expected, data)
+	}
+}
+
+func TestMarshalUnsupportedCatchAll(t *testing.T) {
+	var intStruct struct {
+		Extra map[string]int `env:"*"`
+	}
+	_, err := env.Marshal(intStruct)
+	if err != env.ErrUnsupportedType {
+		t.Errorf("Expected error 'ErrUnsupportedType' but got '%v'", err)
+	}
+
+	var stringStruct struct {
+		Extra string `env:"EXTRA_*"`
+	}
+	_, err = env.Marshal(stringStruct)
+	if err != env.ErrUnsupportedType {
+		t.Errorf("Expected error 'ErrUnsupportedType' but got '%v'", err)
+	}
+}
@@ -0,0 +1,28 @@
+package env
+
+import (
+	"net"
+	"reflect"
+)
+
+func init() {
+	RegisterParser(reflect.TypeOf((*net.TCPAddr)(nil)), parseTCPAddr)
+	RegisterParser(reflect.TypeOf((*net.UDPAddr)(nil)), parseUDPAddr)
+	RegisterParser(reflect.TypeOf((*net.IPNet)(nil)), parseIPNet)
+}
+
+func parseTCPAddr(value string) (interface{}, error) {
+	return net.ResolveTCPAddr("tcp", value)
+}
+
+func parseUDPAddr(value string) (interface{}, error) {
+	return net.ResolveUDPAddr("udp", value)
+}
+
+func parseIPNet(value string) (interface{}, error) {
+	_, ipNet, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, err
+	}
+	return ipNet, nil
+}
@@ -0,0 +1,80 @@
+package env_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+type AddrStruct struct {
+	TCP *net.TCPAddr `env:"LISTEN_TCP"`
+	UDP *net.UDPAddr `env:"LISTEN_UDP"`
+}
+
+func TestUnmarshalAddr(t *testing.T) {
+	m := map[string]string{
+		"LISTEN_TCP": "1.2.3.4:8080",
+		"LISTEN_UDP": "127.0.0.1:53",
+	}
+
+	var addrStruct AddrStruct
+	err := env.UnmarshalMap(m, &addrStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if addrStruct.TCP == nil || addrStruct.TCP.String() != "1.2.3.4:8080" {
+		t.Errorf("Expected field value to be '%s' but got '%v'", "1.2.3.4:8080", addrStruct.TCP)
+	}
+
+	if addrStruct.UDP == nil || addrStruct.UDP.String() != "127.0.0.1:53" {
+		t.Errorf("Expected field value to be '%s' but got '%v'", "127.0.0.1:53", addrStruct.UDP)
+	}
+}
+
+func TestUnmarshalInvalidAddr(t *testing.T) {
+	m := map[string]string{"LISTEN_TCP": "1.2.3.4"}
+
+	var addrStruct AddrStruct
+	err := env.UnmarshalMap(m, &addrStruct)
+	if err == nil || !strings.HasPrefix(err.Error(), "LISTEN_TCP: ") {
+		t.Errorf("Expected error prefixed with '%s' but got '%v'", "LISTEN_TCP: ", err)
+	}
+}
+
+func TestUnmarshalIPNets(t *testing.T) {
+	m := map[string]string{
+		"ALLOWLIST":         "10.0.0.0/8,192.168.1.0/24",
+		"INVALID_ALLOWLIST": "10.0.0.0/8,192.168.1.0/24,192.168.1.0/33",
+	}
+
+	var ipNetStruct struct {
+		Allowlist []*net.IPNet `env:"ALLOWLIST"`
+	}
+	err := env.UnmarshalMap(m, &ipNetStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := []string{"10.0.0.0/8", "192.168.1.0/24"}
+	if len(ipNetStruct.Allowlist) != len(expected) {
+		t.Fatalf("Expected %d elements but got %d", len(expected), len(ipNetStruct.Allowlist))
+	}
+	for i, ipNet := range ipNetStruct.Allowlist {
+		if ipNet.String() != expected[i] {
+			t.Errorf("Expected element value to be '%s' but got '%s'", expected[i], ipNet)
+		}
+	}
+
+	var invalidStruct struct {
+		Allowlist []*net.IPNet `env:"INVALID_ALLOWLIST"`
+	}
+	err = env.UnmarshalMap(m, &invalidStruct)
+
+	expectedErr := "INVALID_ALLOWLIST: index 2: invalid CIDR address: 192.168.1.0/33"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("Expected error '%s' but got '%v'", expectedErr, err)
+	}
+}
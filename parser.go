@@ -0,0 +1,35 @@
+package env
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ParserFunc parses the value of an environment variable into a value of
+// the type it is registered for.
+type ParserFunc func(value string) (interface{}, error)
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = make(map[reflect.Type]ParserFunc)
+)
+
+// RegisterParser registers fn as the parser of fields of type t, replacing
+// any parser previously registered for t. Registered parsers take
+// precedence over the built-in handling of t.
+//
+// The value returned by fn must be assignable to t, otherwise Unmarshal
+// returns ErrUnsupportedType. If t is an interface type, fn may return any
+// concrete type implementing it.
+func RegisterParser(t reflect.Type, fn ParserFunc) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[t] = fn
+}
+
+func lookupParser(t reflect.Type) (ParserFunc, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	fn, ok := parsers[t]
+	return fn, ok
+}
@@ -0,0 +1,107 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+type Level int
+
+type ParserStruct struct {
+	Level        Level  `env:"PARSER_LEVEL"`
+	PointerLevel *Level `env:"PARSER_POINTER_LEVEL"`
+}
+
+type Greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct {
+	name string
+}
+
+func (g englishGreeter) Greet() string {
+	return "Hello, " + g.name
+}
+
+type InterfaceStruct struct {
+	Greeter Greeter `env:"PARSER_GREETER"`
+}
+
+func init() {
+	env.RegisterParser(reflect.TypeOf((*Greeter)(nil)).Elem(), func(value string) (interface{}, error) {
+		return englishGreeter{name: value}, nil
+	})
+
+	env.RegisterParser(reflect.TypeOf(Level(0)), func(value string) (interface{}, error) {
+		switch value {
+		case "debug":
+			return Level(1), nil
+		case "info":
+			return Level(2), nil
+		}
+		return nil, errors.New("unknown level")
+	})
+}
+
+func TestRegisterParser(t *testing.T) {
+	_ = os.Setenv("PARSER_LEVEL", "info")
+	_ = os.Setenv("PARSER_POINTER_LEVEL", "info")
+
+	var parserStruct ParserStruct
+	err := env.Unmarshal(&parserStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if parserStruct.Level != 2 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 2, parserStruct.Level)
+	}
+
+	if parserStruct.PointerLevel == nil {
+		t.Errorf("Expected field value to be '%d' but got '%v'", 2, nil)
+	} else if *parserStruct.PointerLevel != 2 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 2, *parserStruct.PointerLevel)
+	}
+
+	_ = os.Setenv("PARSER_LEVEL", "trace")
+
+	err = env.Unmarshal(&parserStruct)
+	if err == nil || err.Error() != "PARSER_LEVEL: unknown level" {
+		t.Errorf("Expected error '%s' but got '%v'", "PARSER_LEVEL: unknown level", err)
+	}
+}
+
+func TestUnmarshalParseErrorKey(t *testing.T) {
+	_ = os.Setenv("PARSE_ERROR_INT", "one")
+	defer os.Unsetenv("PARSE_ERROR_INT")
+
+	var s struct {
+		Int int `env:"PARSE_ERROR_INT"`
+	}
+	err := env.Unmarshal(&s)
+	if err == nil || !strings.HasPrefix(err.Error(), "PARSE_ERROR_INT: ") {
+		t.Errorf("Expected error wrapped with key 'PARSE_ERROR_INT' but got '%v'", err)
+	}
+}
+
+func TestRegisterParserInterface(t *testing.T) {
+	_ = os.Setenv("PARSER_GREETER", "env")
+
+	var interfaceStruct InterfaceStruct
+	err := env.Unmarshal(&interfaceStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if interfaceStruct.Greeter == nil {
+		t.Errorf("Expected field value to be set but got '%v'", nil)
+	} else if interfaceStruct.Greeter.Greet() != "Hello, env" {
+		t.Errorf("Expected greeting to be '%s' but got '%s'", "Hello, env", interfaceStruct.Greeter.Greet())
+	}
+}
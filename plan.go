@@ -0,0 +1,120 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// stringField is a string field of a flat structure decoded by a plan.
+type stringField struct {
+	index int
+	name  string
+	tag   tag
+}
+
+// stringType is the type of the fields decoded by plans. Named string
+// types are excluded, as parsers may be registered for them.
+var stringType = reflect.TypeOf("")
+
+// stringPlans caches the plans of structure types, nil for types that are
+// not eligible.
+var stringPlans sync.Map
+
+// stringPlan returns the fields of t if every tagged field of t is an
+// exported string, not a catch-all, with only keys, default and required
+// in its tag, and t has no nested structures and implements neither
+// Initializer nor Validator. Such structures are decoded without going
+// through set.
+func stringPlan(t reflect.Type) ([]stringField, bool) {
+	if plan, ok := stringPlans.Load(t); ok {
+		fields := plan.([]stringField)
+		return fields, fields != nil
+	}
+
+	fields := compileStringPlan(t)
+	stringPlans.Store(t, fields)
+	return fields, fields != nil
+}
+
+func compileStringPlan(t reflect.Type) []stringField {
+	if reflect.PtrTo(t).Implements(initializerType) || reflect.PtrTo(t).Implements(validatorType) {
+		return nil
+	}
+
+	fields := []stringField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if indirect(field.Type).Kind() == reflect.Struct {
+			return nil
+		}
+
+		tagString := field.Tag.Get("env")
+		if tagString == "" || tagString == "-" {
+			continue
+		}
+
+		if field.PkgPath != "" || field.Type != stringType {
+			return nil
+		}
+
+		envTag := parseTag(tagString)
+		if strings.HasSuffix(envTag.key(), "*") {
+			return nil
+		}
+		simple := tag{Keys: envTag.Keys, Default: envTag.Default, HasDefault: envTag.HasDefault, Required: envTag.Required}
+		if !reflect.DeepEqual(envTag, simple) {
+			return nil
+		}
+
+		fields = append(fields, stringField{index: i, name: field.Name, tag: envTag})
+	}
+	return fields
+}
+
+// canUsePlan reports whether d has no options changing how a plan decodes,
+// and no parser is registered for strings. Parsers are checked on each
+// call, since they may be registered after a plan is cached.
+func (d *Decoder) canUsePlan() bool {
+	if _, ok := lookupParser(stringType); ok {
+		return false
+	}
+
+	return d.keyTransform == nil &&
+		d.envPrefix == "" &&
+		d.tagOptionSep == "" &&
+		!d.fieldNameFallback &&
+		!d.autoKeys &&
+		!d.caseInsensitiveKeys &&
+		d.onMissing == nil &&
+		!d.blankAsEmpty &&
+		!d.errorOnDuplicateKeys &&
+		!d.fileVariables &&
+		d.valuePolicy == (ValuePolicy{}) &&
+		d.defaults == nil &&
+		d.timing == nil
+}
+
+// decodePlan stores the variables of es in the fields of rv listed by
+// fields, like decode.
+func (d *Decoder) decodePlan(es *envSet, rv reflect.Value, fields []stringField) error {
+	for _, field := range fields {
+		key, value, ok := es.lookup(field.tag.Keys)
+		if !ok {
+			key = field.tag.key()
+		}
+		if !ok || value == DefaultSentinel {
+			switch {
+			case field.tag.HasDefault:
+				value = field.tag.Default
+			case field.tag.Required:
+				return d.fieldError(field.name, key, ErrMissingRequired)
+			default:
+				continue
+			}
+		}
+
+		rv.Field(field.index).SetString(value)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type flatStringStruct struct {
+	Field01 string `env:"FIELD_01"`
+	Field02 string `env:"FIELD_02"`
+	Field03 string `env:"FIELD_03"`
+	Field04 string `env:"FIELD_04"`
+	Field05 string `env:"FIELD_05"`
+	Field06 string `env:"FIELD_06"`
+	Field07 string `env:"FIELD_07"`
+	Field08 string `env:"FIELD_08"`
+	Field09 string `env:"FIELD_09"`
+	Field10 string `env:"FIELD_10"`
+	Field11 string `env:"FIELD_11"`
+	Field12 string `env:"FIELD_12"`
+	Field13 string `env:"FIELD_13"`
+	Field14 string `env:"FIELD_14"`
+	Field15 string `env:"FIELD_15"`
+	Field16 string `env:"FIELD_16,FIELD_ALT_16"`
+	Field17 string `env:"FIELD_17,default=seventeen"`
+	Field18 string `env:"FIELD_18,default=a,b"`
+	Field19 string `env:"FIELD_19"`
+	Field20 string `env:"FIELD_20,required"`
+	Extra   string
+}
+
+func flatStringEnviron() map[string]string {
+	m := make(map[string]string)
+	for i := 1; i <= 20; i++ {
+		key := "FIELD_" + strconv.Itoa(100 + i)[1:]
+		m[key] = "value " + key
+	}
+	delete(m, "FIELD_16")
+	delete(m, "FIELD_17")
+	delete(m, "FIELD_18")
+	m["FIELD_ALT_16"] = "alternative"
+	return m
+}
+
+func TestStringPlan(t *testing.T) {
+	if _, ok := stringPlan(reflect.TypeOf(flatStringStruct{})); !ok {
+		t.Errorf("Expected flat string structure to have a plan")
+	}
+
+	var notEligible struct {
+		Name string `env:"NAME,lower"`
+	}
+	if _, ok := stringPlan(reflect.TypeOf(notEligible)); ok {
+		t.Errorf("Expected structure with options not to have a plan")
+	}
+
+	var notString struct {
+		Port int `env:"PORT"`
+	}
+	if _, ok := stringPlan(reflect.TypeOf(notString)); ok {
+		t.Errorf("Expected structure with an int field not to have a plan")
+	}
+
+	var namedString struct {
+		Name planUpper `env:"NAME"`
+	}
+	if _, ok := stringPlan(reflect.TypeOf(namedString)); ok {
+		t.Errorf("Expected structure with a named string field not to have a plan")
+	}
+
+	var catchAll struct {
+		Extra string `env:"EXTRA_*"`
+	}
+	if _, ok := stringPlan(reflect.TypeOf(catchAll)); ok {
+		t.Errorf("Expected structure with a catch-all field not to have a plan")
+	}
+}
+
+type planUpper string
+
+func TestStringPlanRegisteredParser(t *testing.T) {
+	RegisterParser(reflect.TypeOf(planUpper("")), func(value string) (interface{}, error) {
+		return planUpper(strings.ToUpper(value)), nil
+	})
+
+	var upperStruct struct {
+		A planUpper `env:"A"`
+	}
+	err := UnmarshalMap(map[string]string{"A": "abc"}, &upperStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if upperStruct.A != "ABC" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "ABC", upperStruct.A)
+	}
+
+	var catchAll struct {
+		Extra string `env:"EXTRA_*"`
+	}
+	err = UnmarshalMap(map[string]string{"EXTRA_*": "value"}, &catchAll)
+	if err != ErrUnsupportedType {
+		t.Errorf("Expected error to be '%s' but got '%v'", ErrUnsupportedType, err)
+	}
+}
+
+func TestStringPlanMatchesDecode(t *testing.T) {
+	d := NewDecoder()
+	environs := []map[string]string{
+		flatStringEnviron(),
+		{},
+	}
+
+	for _, environ := range environs {
+		var planned, decoded flatStringStruct
+		fields, _ := stringPlan(reflect.TypeOf(planned))
+
+		planErr := d.decodePlan(newEnvSet(environ), reflect.ValueOf(&planned).Elem(), fields)
+		decodeErr := d.decode(newEnvSet(environ), reflect.ValueOf(&decoded).Elem(), "", "")
+
+		if !reflect.DeepEqual(planned, decoded) {
+			t.Errorf("Expected planned value to be '%+v' but got '%+v'", decoded, planned)
+		}
+
+		if !reflect.DeepEqual(planErr, decodeErr) {
+			t.Errorf("Expected planned error to be '%v' but got '%v'", decodeErr, planErr)
+		}
+	}
+}
+
+func BenchmarkUnmarshalStringPlan(b *testing.B) {
+	environ := flatStringEnviron()
+	d := NewDecoder()
+	for i := 0; i < b.N; i++ {
+		var s flatStringStruct
+		if err := d.unmarshal(newEnvSet(environ), &s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalStringDecode(b *testing.B) {
+	environ := flatStringEnviron()
+	d := NewDecoder()
+	for i := 0; i < b.N; i++ {
+		var s flatStringStruct
+		if err := d.decode(newEnvSet(environ), reflect.ValueOf(&s).Elem(), "", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package env
+
+// Source provides the variables read by a Decoder.
+type Source interface {
+	// Environ returns the variables of the source. The Decoder does not
+	// modify the returned map.
+	Environ() map[string]string
+}
+
+// Map is a set of environment variables. It is a Source, and can be
+// passed to UnmarshalMap.
+type Map map[string]string
+
+// Lookup returns the value of the variable key and reports whether it is
+// set.
+func (m Map) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Set sets the variable key to value.
+func (m Map) Set(key, value string) {
+	m[key] = value
+}
+
+// Environ returns m.
+func (m Map) Environ() map[string]string {
+	return m
+}
@@ -0,0 +1,44 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+func TestMap(t *testing.T) {
+	m := env.Map{"HOME": "/home/map"}
+	m.Set("INT", "5")
+
+	if value, ok := m.Lookup("INT"); !ok || value != "5" {
+		t.Errorf("Expected '%s, %t' but got '%s, %t'", "5", true, value, ok)
+	}
+
+	if value, ok := m.Lookup("MISSING"); ok {
+		t.Errorf("Expected '%s, %t' but got '%s, %t'", "", false, value, ok)
+	}
+
+	var validStruct ValidStruct
+	err := env.NewDecoder(env.WithSource(m)).Unmarshal(&validStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if validStruct.Home != "/home/map" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "/home/map", validStruct.Home)
+	}
+
+	if validStruct.Int != 5 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 5, validStruct.Int)
+	}
+
+	var mapStruct ValidStruct
+	err = env.UnmarshalMap(m, &mapStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if mapStruct.Int != 5 {
+		t.Errorf("Expected field value to be '%d' but got '%d'", 5, mapStruct.Int)
+	}
+}
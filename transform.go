@@ -0,0 +1,29 @@
+package env
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = make(map[string]func(reflect.Value) error)
+)
+
+// RegisterTransform registers fn under name for tags such as
+// `env:"PATH,transform=name"`. Once the value of such a field is set, fn
+// is called with the field to modify it, before the value is validated.
+// An error returned by fn is returned by Unmarshal. Registering a name
+// again replaces the previous function.
+func RegisterTransform(name string, fn func(reflect.Value) error) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = fn
+}
+
+func lookupTransform(name string) (func(reflect.Value) error, bool) {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	fn, ok := transforms[name]
+	return fn, ok
+}
@@ -0,0 +1,43 @@
+package env_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+func TestRegisterTransform(t *testing.T) {
+	env.RegisterTransform("upper", func(f reflect.Value) error {
+		f.SetString(strings.ToUpper(f.String()))
+		return nil
+	})
+
+	m := map[string]string{"TRANSFORM_REGION": "eu-west-1"}
+
+	var transformStruct struct {
+		Region string `env:"TRANSFORM_REGION,transform=upper"`
+	}
+	err := env.UnmarshalMap(m, &transformStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if transformStruct.Region != "EU-WEST-1" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "EU-WEST-1", transformStruct.Region)
+	}
+}
+
+func TestUnknownTransform(t *testing.T) {
+	m := map[string]string{"TRANSFORM_UNKNOWN": "value"}
+
+	var unknownStruct struct {
+		Value string `env:"TRANSFORM_UNKNOWN,transform=unknown"`
+	}
+	err := env.UnmarshalMap(m, &unknownStruct)
+	if !errors.Is(err, env.ErrUnknownTransform) {
+		t.Errorf("Expected error 'ErrUnknownTransform' but got '%v'", err)
+	}
+}
@@ -0,0 +1,23 @@
+package env
+
+import (
+	"net/url"
+	"reflect"
+)
+
+func init() {
+	RegisterParser(reflect.TypeOf(url.URL{}), parseURLValue)
+	RegisterParser(reflect.TypeOf((*url.URL)(nil)), parseURL)
+}
+
+func parseURL(value string) (interface{}, error) {
+	return url.Parse(value)
+}
+
+func parseURLValue(value string) (interface{}, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return *u, nil
+}
@@ -0,0 +1,50 @@
+package env_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+type URLStruct struct {
+	Endpoint *url.URL `env:"ENDPOINT,schemes=https|grpc"`
+	Proxy    url.URL  `env:"PROXY"`
+}
+
+func TestUnmarshalURL(t *testing.T) {
+	m := map[string]string{
+		"ENDPOINT": "grpc://api.example.com:443",
+		"PROXY":    "http://proxy.local:3128",
+	}
+
+	var urlStruct URLStruct
+	err := env.UnmarshalMap(m, &urlStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if urlStruct.Endpoint == nil || urlStruct.Endpoint.Host != "api.example.com:443" {
+		t.Errorf("Expected field value to be '%s' but got '%v'", "api.example.com:443", urlStruct.Endpoint)
+	}
+
+	if urlStruct.Proxy.String() != "http://proxy.local:3128" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "http://proxy.local:3128", urlStruct.Proxy.String())
+	}
+}
+
+func TestUnmarshalURLScheme(t *testing.T) {
+	m := map[string]string{"ENDPOINT": "http://api.example.com"}
+
+	var urlStruct URLStruct
+	err := env.UnmarshalMap(m, &urlStruct)
+	if !errors.Is(err, env.ErrNotAllowed) {
+		t.Errorf("Expected error to be '%s' but got '%s'", env.ErrNotAllowed, err)
+	}
+
+	expected := "ENDPOINT: value is not allowed: scheme http, schemes https|grpc"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%v'", expected, err)
+	}
+}
@@ -0,0 +1,295 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	regexpsMu sync.RWMutex
+	regexps   = make(map[string]*regexp.Regexp)
+)
+
+// validate checks field f, set from value, against the constraints of
+// envTag.
+func validate(f reflect.Value, value string, envTag tag) error {
+	if len(envTag.OneOf) > 0 && !contains(envTag.OneOf, value) {
+		return fmt.Errorf("%w: %s, oneof %s", ErrNotAllowed, value, strings.Join(envTag.OneOf, "|"))
+	}
+
+	if len(envTag.AnyOf) > 0 {
+		err := checkAnyOf(value, envTag.AnyOf)
+		if err != nil {
+			return err
+		}
+	}
+
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+	f = loadAtomic(f)
+
+	if envTag.NotEmpty {
+		if hasLength(f) && f.Len() == 0 || !hasLength(f) && value == "" {
+			return ErrEmptyValue
+		}
+	}
+
+	if envTag.Bool01 {
+		err := checkBool01(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	if envTag.NonNeg {
+		err := checkNonNeg(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	if envTag.Min != "" {
+		err := checkBound(f, "min", envTag.Min)
+		if err != nil {
+			return err
+		}
+	}
+
+	if envTag.Max != "" {
+		err := checkBound(f, "max", envTag.Max)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(envTag.Schemes) > 0 {
+		err := checkScheme(f, envTag.Schemes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if envTag.MaxTotal != "" {
+		err := checkMaxTotal(f, envTag.MaxTotal)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkAnyOf checks that value entirely matches one of the regular
+// expressions patterns.
+func checkAnyOf(value string, patterns []string) error {
+	for _, pattern := range patterns {
+		re, err := compileAnchored(pattern)
+		if err != nil {
+			return fmt.Errorf("anyof: %w", ErrInvalidTag)
+		}
+		if re.MatchString(value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s, anyof %s", ErrNotAllowed, value, strings.Join(patterns, "|"))
+}
+
+// compileAnchored compiles pattern to match an entire value, caching the
+// result.
+func compileAnchored(pattern string) (*regexp.Regexp, error) {
+	regexpsMu.RLock()
+	re, ok := regexps[pattern]
+	regexpsMu.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, err
+	}
+
+	regexpsMu.Lock()
+	defer regexpsMu.Unlock()
+	regexps[pattern] = re
+	return re, nil
+}
+
+// checkBool01 checks that the integer f is 0 or 1.
+func checkBool01(f reflect.Value) error {
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f.Int() != 0 && f.Int() != 1 {
+			return fmt.Errorf("%w: %d, bool01 must be 0 or 1", ErrOutOfRange, f.Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f.Uint() > 1 {
+			return fmt.Errorf("%w: %d, bool01 must be 0 or 1", ErrOutOfRange, f.Uint())
+		}
+	default:
+		return fmt.Errorf("bool01: %w", ErrInvalidTag)
+	}
+	return nil
+}
+
+// checkNonNeg checks that the number f is not negative.
+func checkNonNeg(f reflect.Value) error {
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f.Int() < 0 {
+			return fmt.Errorf("%w: %v, nonneg", ErrOutOfRange, f.Interface())
+		}
+	case reflect.Float32, reflect.Float64:
+		if f.Float() < 0 {
+			return fmt.Errorf("%w: %v, nonneg", ErrOutOfRange, f.Interface())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return fmt.Errorf("nonneg: %w", ErrInvalidTag)
+	}
+	return nil
+}
+
+// checkBound checks f against the bound of the min or max option.
+func checkBound(f reflect.Value, option string, bound string) error {
+	cmp, err := compare(f, bound)
+	if err != nil {
+		return fmt.Errorf("%s: %w", option, ErrInvalidTag)
+	}
+
+	if cmp < 0 && option == "min" || cmp > 0 && option == "max" {
+		if hasLength(f) {
+			return fmt.Errorf("%w: length %d, %s %s", ErrOutOfRange, f.Len(), option, bound)
+		}
+		return fmt.Errorf("%w: %v, %s %s", ErrOutOfRange, f.Interface(), option, bound)
+	}
+	return nil
+}
+
+// checkMaxTotal checks that the sum of the durations of the slice f does
+// not exceed the bound of the maxtotal option. The sum saturates rather
+// than wraps, so durations near the limit cannot add up below the bound.
+func checkMaxTotal(f reflect.Value, bound string) error {
+	maxTotal, err := time.ParseDuration(bound)
+	if err != nil || f.Kind() != reflect.Slice || !isDuration(f.Type().Elem()) {
+		return fmt.Errorf("maxtotal: %w", ErrInvalidTag)
+	}
+
+	var total time.Duration
+	for i := 0; i < f.Len(); i++ {
+		d := time.Duration(f.Index(i).Int())
+		switch {
+		case d > 0 && total > math.MaxInt64-d:
+			total = math.MaxInt64
+		case d < 0 && total < math.MinInt64-d:
+			total = math.MinInt64
+		default:
+			total += d
+		}
+	}
+	if total > maxTotal {
+		return fmt.Errorf("%w: total %s, maxtotal %s", ErrOutOfRange, total, bound)
+	}
+	return nil
+}
+
+// checkScheme checks that the scheme of the URL f is one of schemes,
+// regardless of case.
+func checkScheme(f reflect.Value, schemes []string) error {
+	u, ok := f.Interface().(url.URL)
+	if !ok {
+		return fmt.Errorf("schemes: %w", ErrInvalidTag)
+	}
+
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: scheme %s, schemes %s", ErrNotAllowed, u.Scheme, strings.Join(schemes, "|"))
+}
+
+// hasLength reports whether f is compared by length rather than value.
+func hasLength(f reflect.Value) bool {
+	switch f.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// compare compares f with bound, returning -1, 0 or 1. Numbers are
+// compared by value; strings, slices, arrays and maps by length.
+func compare(f reflect.Value, bound string) (int, error) {
+	if hasLength(f) {
+		n, err := strconv.ParseInt(bound, 10, 64)
+		return compareInt(int64(f.Len()), n), err
+	}
+
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isDuration(f.Type()) {
+			d, err := time.ParseDuration(bound)
+			return compareInt(f.Int(), int64(d)), err
+		}
+		n, err := strconv.ParseInt(bound, 10, 64)
+		return compareInt(f.Int(), n), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(bound, 10, 64)
+		return compareUint(f.Uint(), n), err
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(bound, 64)
+		return compareFloat(f.Float(), n), err
+	}
+	return 0, ErrInvalidTag
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+type RangeStruct struct {
+	Shards  []string      `env:"SHARDS,min=2,max=8"`
+	Workers int           `env:"WORKERS,min=1,max=16"`
+	Timeout time.Duration `env:"TIMEOUT,min=1s,max=1m"`
+	Name    string        `env:"NAME,max=5"`
+}
+
+func TestUnmarshalRange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		environ  map[string]string
+		expected error
+	}{
+		{"valid", map[string]string{"SHARDS": "a,b,c", "WORKERS": "4", "TIMEOUT": "30s", "NAME": "env"}, nil},
+		{"too few elements", map[string]string{"SHARDS": "a"}, env.ErrOutOfRange},
+		{"too many elements", map[string]string{"SHARDS": "a,b,c,d,e,f,g,h,i"}, env.ErrOutOfRange},
+		{"number below min", map[string]string{"WORKERS": "0"}, env.ErrOutOfRange},
+		{"number above max", map[string]string{"WORKERS": "17"}, env.ErrOutOfRange},
+		{"duration above max", map[string]string{"TIMEOUT": "2m"}, env.ErrOutOfRange},
+		{"string too long", map[string]string{"NAME": "too long"}, env.ErrOutOfRange},
+	}
+
+	for _, testCase := range testCases {
+		var rangeStruct RangeStruct
+		err := env.UnmarshalMap(testCase.environ, &rangeStruct)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%s: Expected error '%v' but got '%v'", testCase.name, testCase.expected, err)
+		}
+	}
+}
+
+func TestUnmarshalRangeInvalidTag(t *testing.T) {
+	var invalidStruct struct {
+		Workers int `env:"WORKERS,min=one"`
+	}
+	err := env.UnmarshalMap(map[string]string{"WORKERS": "1"}, &invalidStruct)
+	if !errors.Is(err, env.ErrInvalidTag) {
+		t.Errorf("Expected error 'ErrInvalidTag' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalBool01(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected int
+		err      error
+	}{
+		{"0", 0, nil},
+		{"1", 1, nil},
+		{"2", 0, env.ErrOutOfRange},
+	}
+
+	for _, testCase := range testCases {
+		var bool01Struct struct {
+			Enabled int `env:"ENABLED,bool01"`
+		}
+		err := env.UnmarshalMap(map[string]string{"ENABLED": testCase.value}, &bool01Struct)
+		if !errors.Is(err, testCase.err) {
+			t.Errorf("%s: Expected error '%v' but got '%v'", testCase.value, testCase.err, err)
+		}
+
+		if err == nil && bool01Struct.Enabled != testCase.expected {
+			t.Errorf("%s: Expected field value to be '%d' but got '%d'", testCase.value, testCase.expected, bool01Struct.Enabled)
+		}
+	}
+}
+
+type Environment string
+
+func TestUnmarshalOneOfNamedString(t *testing.T) {
+	var oneOfStruct struct {
+		Environment Environment `env:"ENVIRONMENT,oneof=dev|staging|prod"`
+	}
+
+	err := env.UnmarshalMap(map[string]string{"ENVIRONMENT": "staging"}, &oneOfStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if oneOfStruct.Environment != "staging" {
+		t.Errorf("Expected field value to be '%s' but got '%s'", "staging", oneOfStruct.Environment)
+	}
+
+	err = env.UnmarshalMap(map[string]string{"ENVIRONMENT": "qa"}, &oneOfStruct)
+	if !errors.Is(err, env.ErrNotAllowed) {
+		t.Errorf("Expected error 'ErrNotAllowed' but got '%v'", err)
+	}
+}
+
+func TestUnmarshalNotEmptyCollections(t *testing.T) {
+	testCases := []struct {
+		value string
+		err   error
+	}{
+		{"", env.ErrEmptyValue},
+		{",", env.ErrEmptyValue},
+		{"a,b", nil},
+	}
+
+	for _, testCase := range testCases {
+		var notEmptyStruct struct {
+			Upstreams []string            `env:"UPSTREAMS,trimempty,notempty"`
+			Labels    map[string]struct{} `env:"UPSTREAMS,trimempty,notempty"`
+		}
+		err := env.UnmarshalMap(map[string]string{"UPSTREAMS": testCase.value}, &notEmptyStruct)
+		if !errors.Is(err, testCase.err) {
+			t.Errorf("'%s': Expected error '%v' but got '%v'", testCase.value, testCase.err, err)
+		}
+
+		if err == nil && (len(notEmptyStruct.Upstreams) != 2 || len(notEmptyStruct.Labels) != 2) {
+			t.Errorf("'%s': Expected '%d' elements but got '%v' and '%v'", testCase.value, 2, notEmptyStruct.Upstreams, notEmptyStruct.Labels)
+		}
+	}
+}
+
+func TestUnmarshalMaxTotal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected error
+	}{
+		{"within cap", "1s,10s,30s", nil},
+		{"equal to cap", "30s,30s", nil},
+		{"above cap", "10s,20s,40s", env.ErrOutOfRange},
+		{"overflowing", "2562047h,2562047h", env.ErrOutOfRange},
+	}
+
+	for _, testCase := range testCases {
+		var backoffStruct struct {
+			Backoff []time.Duration `env:"BACKOFF,sep=,,maxtotal=1m"`
+		}
+		err := env.UnmarshalMap(map[string]string{"BACKOFF": testCase.value}, &backoffStruct)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%s: Expected error '%v' but got '%v'", testCase.name, testCase.expected, err)
+		}
+	}
+
+	var invalidStruct struct {
+		Backoff []int `env:"BACKOFF,maxtotal=1m"`
+	}
+	err := env.UnmarshalMap(map[string]string{"BACKOFF": "1,2"}, &invalidStruct)
+	if !errors.Is(err, env.ErrInvalidTag) {
+		t.Errorf("Expected error to be '%s' but got '%s'", env.ErrInvalidTag, err)
+	}
+}
+
+func TestUnmarshalNonNeg(t *testing.T) {
+	type nonNegStruct struct {
+		Timeout time.Duration `env:"NONNEG_TIMEOUT,nonneg"`
+		Ratio   float64       `env:"NONNEG_RATIO,nonneg"`
+	}
+
+	testCases := []struct {
+		name     string
+		environ  map[string]string
+		expected error
+	}{
+		{"positive duration", map[string]string{"NONNEG_TIMEOUT": "5s"}, nil},
+		{"zero duration", map[string]string{"NONNEG_TIMEOUT": "0s"}, nil},
+		{"negative duration", map[string]string{"NONNEG_TIMEOUT": "-5s"}, env.ErrOutOfRange},
+		{"negative float", map[string]string{"NONNEG_RATIO": "-0.5"}, env.ErrOutOfRange},
+	}
+
+	for _, testCase := range testCases {
+		var s nonNegStruct
+		err := env.UnmarshalMap(testCase.environ, &s)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%s: Expected error '%v' but got '%v'", testCase.name, testCase.expected, err)
+		}
+	}
+
+	var s nonNegStruct
+	err := env.UnmarshalMap(map[string]string{"NONNEG_TIMEOUT": "-5s"}, &s)
+
+	expected := "NONNEG_TIMEOUT: value is out of range: -5s, nonneg"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%v'", expected, err)
+	}
+}
+
+func TestUnmarshalAnyOf(t *testing.T) {
+	type anyOfStruct struct {
+		Host string `env:"ANYOF_HOST,anyof=[0-9]+(\\.[0-9]+){3}|[a-z][a-z0-9.-]*"`
+	}
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected error
+	}{
+		{"first pattern", "10.0.0.1", nil},
+		{"second pattern", "db.local", nil},
+		{"no pattern", "DB_LOCAL", env.ErrNotAllowed},
+		{"partial match", "db.local/path", env.ErrNotAllowed},
+	}
+
+	for _, testCase := range testCases {
+		var s anyOfStruct
+		err := env.UnmarshalMap(map[string]string{"ANYOF_HOST": testCase.value}, &s)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%s: Expected error '%v' but got '%v'", testCase.name, testCase.expected, err)
+		}
+	}
+
+	var invalidStruct struct {
+		Host string `env:"ANYOF_HOST,anyof=[a-z"`
+	}
+	err := env.UnmarshalMap(map[string]string{"ANYOF_HOST": "db"}, &invalidStruct)
+	if !errors.Is(err, env.ErrInvalidTag) {
+		t.Errorf("Expected error to be '%s' but got '%v'", env.ErrInvalidTag, err)
+	}
+}
@@ -0,0 +1,62 @@
+package env_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+var errPortRange = errors.New("port must be between 1 and 65535")
+
+type ServerValidatorConfig struct {
+	Port int `env:"VALIDATOR_PORT"`
+
+	Address string
+}
+
+func (c *ServerValidatorConfig) AfterUnmarshal() error {
+	c.Address = ":" + strconv.Itoa(c.Port)
+	return nil
+}
+
+func (c *ServerValidatorConfig) Validate() error {
+	if c.Address == "" {
+		return errors.New("Validate called before AfterUnmarshal")
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return errPortRange
+	}
+	return nil
+}
+
+type ValidatorStruct struct {
+	Server ServerValidatorConfig
+}
+
+func TestUnmarshalValidator(t *testing.T) {
+	var validatorStruct ValidatorStruct
+	err := env.UnmarshalMap(map[string]string{"VALIDATOR_PORT": "8080"}, &validatorStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	err = env.UnmarshalMap(map[string]string{"VALIDATOR_PORT": "70000"}, &validatorStruct)
+	if !errors.Is(err, errPortRange) {
+		t.Errorf("Expected error 'errPortRange' but got '%v'", err)
+	}
+
+	expected := "Server: port must be between 1 and 65535"
+	if err != nil && err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%s'", expected, err)
+	}
+
+	var configStruct ServerValidatorConfig
+	err = env.UnmarshalMap(map[string]string{"VALIDATOR_PORT": "0"}, &configStruct)
+
+	expected = "ServerValidatorConfig: port must be between 1 and 65535"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error '%s' but got '%v'", expected, err)
+	}
+}
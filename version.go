@@ -0,0 +1,72 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion returned when the value of a Version field is not in
+// the form major.minor.patch.
+var ErrInvalidVersion = errors.New("version must be in the form major.minor.patch")
+
+// Version is a semantic version such as 1.2.3, without pre-release or
+// build metadata.
+type Version struct {
+	Major, Minor, Patch uint64
+}
+
+func init() {
+	RegisterParser(reflect.TypeOf(Version{}), func(value string) (interface{}, error) {
+		return ParseVersion(value)
+	})
+}
+
+// ParseVersion parses a version in the form major.minor.patch, with an
+// optional "v" prefix.
+func ParseVersion(value string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(value, "v"), ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("%q: %w", value, ErrInvalidVersion)
+	}
+
+	var numbers [3]uint64
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("%q: %w", value, ErrInvalidVersion)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Compare returns -1 if v is lower than other, 1 if it is greater and 0 if
+// they are equal.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]uint64{
+		{v.Major, other.Major},
+		{v.Minor, other.Minor},
+		{v.Patch, other.Patch},
+	} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Less reports whether v is lower than other.
+func (v Version) Less(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// String returns v in the form major.minor.patch.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
@@ -0,0 +1,64 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+func TestUnmarshalVersion(t *testing.T) {
+	m := map[string]string{
+		"MIN_VERSION": "1.2.3",
+		"MAX_VERSION": "v1.10.0",
+	}
+
+	var versionStruct struct {
+		Min env.Version  `env:"MIN_VERSION"`
+		Max *env.Version `env:"MAX_VERSION"`
+	}
+	err := env.UnmarshalMap(m, &versionStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	expected := env.Version{Major: 1, Minor: 2, Patch: 3}
+	if versionStruct.Min != expected {
+		t.Errorf("Expected field value to be '%s' but got '%s'", expected, versionStruct.Min)
+	}
+
+	if versionStruct.Max == nil || versionStruct.Max.String() != "1.10.0" {
+		t.Errorf("Expected field value to be '%s' but got '%v'", "1.10.0", versionStruct.Max)
+	}
+
+	for _, value := range []string{"1.2", "1.2.x", "1.2.3.4", ""} {
+		var invalidStruct struct {
+			Min env.Version `env:"MIN_VERSION"`
+		}
+		err = env.UnmarshalMap(map[string]string{"MIN_VERSION": value}, &invalidStruct)
+		if !errors.Is(err, env.ErrInvalidVersion) {
+			t.Errorf("Expected error 'ErrInvalidVersion' for '%s' but got '%v'", value, err)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	v1 := env.Version{Major: 1, Minor: 2, Patch: 3}
+	v2 := env.Version{Major: 1, Minor: 10, Patch: 0}
+
+	if c := v1.Compare(v2); c != -1 {
+		t.Errorf("Expected comparison to be '%d' but got '%d'", -1, c)
+	}
+
+	if c := v2.Compare(v1); c != 1 {
+		t.Errorf("Expected comparison to be '%d' but got '%d'", 1, c)
+	}
+
+	if c := v1.Compare(v1); c != 0 {
+		t.Errorf("Expected comparison to be '%d' but got '%d'", 0, c)
+	}
+
+	if !v1.Less(v2) || v2.Less(v1) {
+		t.Errorf("Expected '%s' to be less than '%s'", v1, v2)
+	}
+}
@@ -0,0 +1,24 @@
+package env
+
+import (
+	"io"
+	"os"
+	"reflect"
+)
+
+func init() {
+	RegisterParser(reflect.TypeOf((*io.Writer)(nil)).Elem(), parseWriter)
+}
+
+// parseWriter returns os.Stdout for "stdout", os.Stderr for "stderr", or
+// the file named by value opened for appending, created if needed. The
+// caller is responsible for closing the file.
+func parseWriter(value string) (interface{}, error) {
+	switch value {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	return os.OpenFile(value, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+}
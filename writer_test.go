@@ -0,0 +1,76 @@
+package env_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/serge64/env"
+)
+
+type WriterStruct struct {
+	Dest io.Writer `env:"LOG_DEST,default=stderr"`
+}
+
+func TestUnmarshalWriter(t *testing.T) {
+	var writerStruct WriterStruct
+	err := env.UnmarshalMap(map[string]string{"LOG_DEST": "stdout"}, &writerStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if writerStruct.Dest != os.Stdout {
+		t.Errorf("Expected field value to be '%v' but got '%v'", os.Stdout, writerStruct.Dest)
+	}
+
+	err = env.UnmarshalMap(map[string]string{}, &writerStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if writerStruct.Dest != os.Stderr {
+		t.Errorf("Expected field value to be '%v' but got '%v'", os.Stderr, writerStruct.Dest)
+	}
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	err = env.UnmarshalMap(map[string]string{"LOG_DEST": filename}, &writerStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	f, ok := writerStruct.Dest.(*os.File)
+	if !ok {
+		t.Fatalf("Expected field value to be a file but got '%T'", writerStruct.Dest)
+	}
+	_, err = io.WriteString(f, "started\n")
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+	f.Close()
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "started\n" {
+		t.Errorf("Expected file content to be '%s' but got '%s'", "started\n", data)
+	}
+}
+
+func TestUnmarshalWriterOpenError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "missing", "app.log")
+
+	var writerStruct WriterStruct
+	err := env.UnmarshalMap(map[string]string{"LOG_DEST": filename}, &writerStruct)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected error 'ErrNotExist' but got '%v'", err)
+	}
+
+	if err != nil && !strings.HasPrefix(err.Error(), "LOG_DEST: ") {
+		t.Errorf("Expected error to report the key but got '%s'", err)
+	}
+}
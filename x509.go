@@ -0,0 +1,24 @@
+package env
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"reflect"
+)
+
+// ErrInvalidPEM returned when the value of a *x509.Certificate field has
+// no PEM block.
+var ErrInvalidPEM = errors.New("value must be PEM encoded")
+
+func init() {
+	RegisterParser(reflect.TypeOf((*x509.Certificate)(nil)), parseCertificate)
+}
+
+func parseCertificate(value string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,67 @@
+package env_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/serge64/env"
+)
+
+type CertificateStruct struct {
+	Certificate *x509.Certificate `env:"TLS_CERTIFICATE"`
+}
+
+func selfSignedCertificate(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "env test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestUnmarshalCertificate(t *testing.T) {
+	_ = os.Setenv("TLS_CERTIFICATE", string(selfSignedCertificate(t)))
+
+	var certificateStruct CertificateStruct
+	err := env.Unmarshal(&certificateStruct)
+	if err != nil {
+		t.Errorf("Expected no error but got '%s'", err)
+	}
+
+	if certificateStruct.Certificate == nil {
+		t.Errorf("Expected certificate but got '%v'", nil)
+	} else if certificateStruct.Certificate.Subject.CommonName != "env test" {
+		t.Errorf("Expected common name to be '%s' but got '%s'", "env test", certificateStruct.Certificate.Subject.CommonName)
+	}
+
+	_ = os.Setenv("TLS_CERTIFICATE", "not a certificate")
+
+	err = env.Unmarshal(&certificateStruct)
+	if !errors.Is(err, env.ErrInvalidPEM) {
+		t.Errorf("Expected error 'ErrInvalidPEM' but got '%v'", err)
+	}
+}